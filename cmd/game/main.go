@@ -1,42 +1,90 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
+	"os"
 	"time"
 
-	"github.com/alfiehiscox/submarines/pkg/board"
-	"github.com/alfiehiscox/submarines/pkg/player"
+	"github.com/alfiehiscox/submarines/pkg/cell"
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/alfiehiscox/submarines/pkg/storage"
 )
 
 func main() {
-	p1 := player.NewPlayer("player 1")
-	p1.RandomizePlacement()
-	p2 := player.NewPlayer("player 2")
-	p2.RandomizePlacement()
+	db := flag.String("db", "game.db", "file to store the game in, a game left unfinished there carries on")
+	flag.Parse()
 
-	var winner *player.Player
+	log := slog.New(slog.NewTextHandler(os.Stderr, nil))
 
-	turn_player := p1
-	enemy_player := p2
+	repo, err := storage.OpenBolt(*db)
+	if err != nil {
+		log.Error("Error opening database:", "error", err)
+		os.Exit(1)
+	}
+	defer repo.Close()
 
-	for {
+	g, err := load(repo)
+	if err != nil {
+		log.Error("Error loading game:", "error", err)
+		return
+	}
 
-		coord := turn_player.GetGuess()
+	persisted := make(chan struct{})
+	go func() {
+		storage.Persist(repo, g, log)
+		close(persisted)
+	}()
 
-		hit := enemy_player.CheckHit(coord)
-		turn_player.MarkTargetAttempt(coord, hit)
-		enemy_player.MarkPlayerAttempt(coord, hit)
+	for g.Snapshot().Phase == game.PLAYING {
+		turn := g.Snapshot().Turn
 
-		if board.CheckWinner(turn_player.TargetBoard, enemy_player.PlayerBoard) {
-			winner = turn_player
-			break
+		_, err := g.Fire(turn, cell.GetRandomCoord(0))
+		if errors.Is(err, game.ErrAlreadyFired) {
+			continue
+		}
+		if err != nil {
+			log.Error("Error firing:", "error", err)
+			return
 		}
 
-		turn_player, enemy_player = enemy_player, turn_player
 		time.Sleep(time.Second)
 	}
 
-	if winner != nil {
-		fmt.Printf("The winner is %s!\n", winner.Name)
+	<-persisted
+
+	s := g.Snapshot()
+	if s.Winner != game.NO_WINNER {
+		fmt.Printf("The winner is %s!\n", s.Names[s.Winner])
+	}
+}
+
+// Returns the most recent unfinished game in repo, or starts a new one.
+func load(repo storage.Repository) (*game.Game, error) {
+	records, err := repo.Games()
+	if err != nil {
+		return nil, err
 	}
+
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].Phase != game.FINISHED {
+			return game.Restore(records[i]), nil
+		}
+	}
+
+	id, err := game.NewID()
+	if err != nil {
+		return nil, err
+	}
+
+	g := game.New(id)
+	for seat := 0; seat < game.SEATS; seat++ {
+		g.Join(fmt.Sprintf("player %d", seat+1))
+		g.RandomizeFleet(seat)
+		g.Ready(seat)
+	}
+
+	return g, nil
 }
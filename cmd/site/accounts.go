@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/alfiehiscox/submarines/pkg/account"
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/alfiehiscox/submarines/pkg/html"
+	"github.com/alfiehiscox/submarines/pkg/storage"
+	. "maragu.dev/gomponents"
+)
+
+const (
+	SESSION_COOKIE = "session"
+
+	// Double submit: static/csrf.js copies the cookie into the header of
+	// every htmx request, which another site can't do
+	CSRF_COOKIE = "csrf"
+	CSRF_HEADER = "X-CSRF-Token"
+)
+
+type accountKey struct{}
+
+// Loads the account of the request's session, if it has one.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(SESSION_COOKIE)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		session, err := s.repo.Session(account.HashToken(cookie.Value))
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		a, err := s.repo.Account(session.Account)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), accountKey{}, a)))
+	})
+}
+
+// Rejects requests that change anything unless they carry the CSRF
+// cookie in their header, and hands out the cookie to those without.
+// Only htmx sends the header, see static/csrf.js, so handlers behind this
+// never see plain form posts.
+func verifyCSRF(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(CSRF_COOKIE)
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			if err != nil {
+				if err := setCSRFCookie(w); err != nil {
+					http.Error(w, "could not start session", http.StatusInternalServerError)
+					return
+				}
+			}
+
+		default:
+			header := r.Header.Get(CSRF_HEADER)
+			if err != nil || header == "" || subtle.ConstantTimeCompare([]byte(header), []byte(cookie.Value)) != 1 {
+				http.Error(w, "invalid CSRF token", http.StatusForbidden)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func setCSRFCookie(w http.ResponseWriter) error {
+	token, err := game.NewID()
+	if err != nil {
+		return err
+	}
+
+	// Not HttpOnly, the page has to read it
+	http.SetCookie(w, &http.Cookie{
+		Name:     CSRF_COOKIE,
+		Value:    token,
+		Path:     "/",
+		SameSite: http.SameSiteStrictMode,
+	})
+	return nil
+}
+
+// Returns the logged in account.
+func accountFrom(r *http.Request) (account.Account, bool) {
+	a, ok := r.Context().Value(accountKey{}).(account.Account)
+	return a, ok
+}
+
+// Returns the logged in account, or logs the browser in to a new guest
+// account so what it plays can be kept if it registers later.
+func (s *Server) identify(w http.ResponseWriter, r *http.Request) (account.Account, error) {
+	if a, ok := accountFrom(r); ok {
+		return a, nil
+	}
+
+	guest, err := account.NewGuest()
+	if err != nil {
+		return account.Account{}, err
+	}
+
+	if err := s.repo.SaveAccount(guest); err != nil {
+		return account.Account{}, err
+	}
+
+	return guest, s.startSession(w, r, guest)
+}
+
+func (s *Server) startSession(w http.ResponseWriter, r *http.Request, a account.Account) error {
+	session, token, err := account.NewSession(a)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.SaveSession(session); err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     SESSION_COOKIE,
+		Value:    token,
+		Path:     "/",
+		Expires:  session.Expires,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+func (s *Server) endSession(w http.ResponseWriter, r *http.Request) error {
+	if cookie, err := r.Cookie(SESSION_COOKIE); err == nil {
+		if err := s.repo.DeleteSession(account.HashToken(cookie.Value)); err != nil {
+			return err
+		}
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: SESSION_COOKIE, Path: "/", MaxAge: -1})
+	return nil
+}
+
+func RegisterHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
+	return html.Register(locale(r), ""), nil
+}
+
+// Registers the browser's guest account, or a new one if it has none.
+func (s *Server) RegisterFormHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
+	a, err := s.identify(w, r)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.Register(r.PostFormValue("name"), r.PostFormValue("password"))
+	if err == nil {
+		err = s.repo.SaveAccount(a)
+	}
+
+	problem := ""
+	switch {
+	case errors.Is(err, account.ErrRegistered), errors.Is(err, account.ErrInvalidName),
+		errors.Is(err, account.ErrInvalidPassword), errors.Is(err, storage.ErrNameTaken):
+		problem = s.problem(r, err)
+	case err != nil:
+		return nil, err
+	}
+	if problem != "" {
+		return render(w, r, html.RegisterForm(locale(r), problem), html.Register(locale(r), problem)), nil
+	}
+
+	redirect(w, r, "/")
+	return nil, nil
+}
+
+func LoginHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
+	return html.Login(locale(r), ""), nil
+}
+
+func (s *Server) LoginFormHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
+	var found *account.Account
+	a, err := s.repo.AccountByName(r.PostFormValue("name"))
+	if err == nil {
+		found = &a
+	} else if !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+
+	if err := account.CheckPassword(found, r.PostFormValue("password")); err != nil {
+		problem := s.problem(r, err)
+		return render(w, r, html.LoginForm(locale(r), problem), html.Login(locale(r), problem)), nil
+	}
+
+	if err := s.endSession(w, r); err != nil {
+		return nil, err
+	}
+
+	if err := s.startSession(w, r, a); err != nil {
+		return nil, err
+	}
+
+	redirect(w, r, "/")
+	return nil, nil
+}
+
+func (s *Server) LogoutHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
+	if err := s.endSession(w, r); err != nil {
+		return nil, err
+	}
+
+	redirect(w, r, "/")
+	return nil, nil
+}
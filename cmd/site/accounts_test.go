@@ -0,0 +1,134 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/alfiehiscox/submarines/pkg/storage"
+)
+
+type browser struct {
+	t      *testing.T
+	client *http.Client
+	base   string
+}
+
+func newBrowser(t *testing.T, base string) *browser {
+	t.Helper()
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatalf("failed in set up: %s", err)
+	}
+
+	client := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	return &browser{t: t, client: client, base: base}
+}
+
+func (b *browser) get(path string) string {
+	b.t.Helper()
+
+	res, err := b.client.Get(b.base + path)
+	if err != nil {
+		b.t.Fatalf("request failed: %s", err)
+	}
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(res.Body)
+	return string(body)
+}
+
+// Posts form the way htmx would, with the CSRF header if csrf is set.
+func (b *browser) post(path string, form url.Values, csrf bool) *http.Response {
+	b.t.Helper()
+
+	req, err := http.NewRequest("POST", b.base+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		b.t.Fatalf("failed in set up: %s", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+
+	if csrf {
+		u, _ := url.Parse(b.base)
+		for _, cookie := range b.client.Jar.Cookies(u) {
+			if cookie.Name == CSRF_COOKIE {
+				req.Header.Set(CSRF_HEADER, cookie.Value)
+			}
+		}
+	}
+
+	res, err := b.client.Do(req)
+	if err != nil {
+		b.t.Fatalf("request failed: %s", err)
+	}
+	res.Body.Close()
+	return res
+}
+
+func TestGuestRegistersAndLogsIn(t *testing.T) {
+	repo := storage.NewMemory()
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{Repository: repo})
+	s.setUpRoutes()
+	ts := httptest.NewServer(s.mux)
+	defer ts.Close()
+
+	b := newBrowser(t, ts.URL)
+	b.get("/")
+
+	if res := b.post("/games", nil, false); res.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 without a CSRF token, got %d", res.StatusCode)
+	}
+
+	res := b.post("/games", nil, true)
+	id := strings.TrimPrefix(res.Header.Get("HX-Redirect"), "/games/")
+	g, ok := s.games.Get(id)
+	if !ok {
+		t.Fatalf("expected a redirect to the new game, got %q", res.Header.Get("HX-Redirect"))
+	}
+
+	if page := b.get("/"); !strings.Contains(page, "Playing as Guest-") {
+		t.Fatal("expected to be playing as a guest")
+	}
+
+	res = b.post("/register", url.Values{"name": {"admiral"}, "password": {"hunter22"}}, true)
+	if res.Header.Get("HX-Redirect") != "/" {
+		t.Fatalf("expected to register, got %d", res.StatusCode)
+	}
+
+	a, err := repo.AccountByName("admiral")
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	if seat := g.Record().Seats[0]; seat.Account != a.ID || a.Guest {
+		t.Fatal("expected the guest's game to belong to the registered account")
+	}
+
+	b.post("/logout", nil, true)
+	if page := b.get("/"); strings.Contains(page, "admiral") {
+		t.Fatal("expected to be logged out")
+	}
+
+	b.post("/login", url.Values{"name": {"admiral"}, "password": {"wrong password"}}, true)
+	if page := b.get("/"); strings.Contains(page, "admiral") {
+		t.Fatal("expected a wrong password to be refused")
+	}
+
+	b.post("/login", url.Values{"name": {"Admiral"}, "password": {"hunter22"}}, true)
+	if page := b.get("/"); !strings.Contains(page, "Logged in as") || !strings.Contains(page, "admiral") {
+		t.Fatal("expected to be logged in")
+	}
+}
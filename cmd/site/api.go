@@ -0,0 +1,336 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/alfiehiscox/submarines/pkg/api"
+	"github.com/alfiehiscox/submarines/pkg/cell"
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/alfiehiscox/submarines/pkg/player"
+	"github.com/alfiehiscox/submarines/pkg/storage"
+	"github.com/go-chi/chi/v5"
+)
+
+// Largest request body the API will read
+const MAX_API_BODY = 1 << 16
+
+var (
+	errUnauthorized   = errors.New("missing or invalid seat token")
+	errInvalidRequest = errors.New("invalid request body")
+)
+
+func (s *Server) setUpAPIRoutes(r chi.Router) {
+	r.Use(s.limit(PAGE_BUDGET))
+
+	r.Post("/games", s.APICreateGameHandler)
+	r.Get("/games/{id}", s.APIGameHandler)
+	r.Post("/games/{id}/join", s.APIJoinGameHandler)
+	r.Put("/games/{id}/fleet", s.APIFleetHandler)
+	r.Post("/games/{id}/ready", s.APIReadyHandler)
+	r.With(s.limit(SHOT_BUDGET)).Post("/games/{id}/shots", s.APIShotHandler)
+	r.Get("/games/{id}/moves", s.APIMovesHandler)
+	r.Get("/bots", s.BotGatewayHandler)
+}
+
+func (s *Server) APICreateGameHandler(w http.ResponseWriter, r *http.Request) {
+	var req api.JoinRequest
+	if err := readJSON(r, &req); err != nil {
+		s.writeAPIError(w, r, err)
+		return
+	}
+
+	g, err := s.games.Create()
+	if err != nil {
+		s.writeAPIError(w, r, err)
+		return
+	}
+
+	s.apiJoin(w, r, g, req.Name, http.StatusCreated)
+}
+
+func (s *Server) APIJoinGameHandler(w http.ResponseWriter, r *http.Request) {
+	var req api.JoinRequest
+	if err := readJSON(r, &req); err != nil {
+		s.writeAPIError(w, r, err)
+		return
+	}
+
+	g, err := s.game(r)
+	if err != nil {
+		s.writeAPIError(w, r, err)
+		return
+	}
+
+	s.apiJoin(w, r, g, req.Name, http.StatusOK)
+}
+
+// Returns the game as seen by the seat token holder, or as a spectator
+// sees it if there is no token.
+func (s *Server) APIGameHandler(w http.ResponseWriter, r *http.Request) {
+	g, err := s.game(r)
+	if err != nil {
+		s.writeAPIError(w, r, err)
+		return
+	}
+
+	seat, ok := seatFromBearer(r, g)
+	if !ok && bearer(r) != "" {
+		s.writeAPIError(w, r, errUnauthorized)
+		return
+	}
+
+	if ok {
+		writeJSON(w, http.StatusOK, apiGame(g, &seat))
+	} else {
+		writeJSON(w, http.StatusOK, apiGame(g, nil))
+	}
+}
+
+func (s *Server) APIFleetHandler(w http.ResponseWriter, r *http.Request) {
+	var req api.FleetRequest
+	if err := readJSON(r, &req); err != nil {
+		s.writeAPIError(w, r, err)
+		return
+	}
+
+	s.apiSeatAction(w, r, func(g *game.Game, seat int) error {
+		if req.Random {
+			return g.For(requestID(r)).RandomizeFleet(seat)
+		}
+
+		ships := make([]game.Ship, len(req.Ships))
+		for i, ship := range req.Ships {
+			ships[i] = game.Ship{
+				Size:        ship.Size,
+				Orientation: cell.Orientation(strings.ToUpper(ship.Orientation)),
+				Coordinate:  cell.Coordinate{ship.X, ship.Y},
+			}
+		}
+		return g.For(requestID(r)).PlaceFleet(seat, ships)
+	})
+}
+
+func (s *Server) APIReadyHandler(w http.ResponseWriter, r *http.Request) {
+	s.apiSeatAction(w, r, func(g *game.Game, seat int) error {
+		return g.For(requestID(r)).Ready(seat)
+	})
+}
+
+func (s *Server) APIShotHandler(w http.ResponseWriter, r *http.Request) {
+	var req api.ShotRequest
+	if err := readJSON(r, &req); err != nil {
+		s.writeAPIError(w, r, err)
+		return
+	}
+
+	coord, err := cell.NewCoordinate(req.X, req.Y)
+	if err != nil {
+		s.strike(r, err)
+		s.writeAPIError(w, r, err)
+		return
+	}
+
+	s.apiSeatAction(w, r, func(g *game.Game, seat int) error {
+		_, err := g.For(requestID(r)).Fire(seat, coord)
+		s.strike(r, err)
+		return err
+	})
+}
+
+// Lists the moves of a game, optionally only those after the first
+// `since` moves so clients can poll for new ones.
+func (s *Server) APIMovesHandler(w http.ResponseWriter, r *http.Request) {
+	g, err := s.game(r)
+	if err != nil {
+		s.writeAPIError(w, r, err)
+		return
+	}
+
+	since := 0
+	if value := r.URL.Query().Get("since"); value != "" {
+		since, err = strconv.Atoi(value)
+		if err != nil || since < 0 {
+			s.writeAPIError(w, r, errInvalidRequest)
+			return
+		}
+	}
+
+	moves := apiMoves(g.Snapshot().Moves)
+	writeJSON(w, http.StatusOK, api.MovesResponse{Moves: moves[min(since, len(moves)):]})
+}
+
+func (s *Server) apiJoin(w http.ResponseWriter, r *http.Request, g *game.Game, name string, status int) {
+	if name == "" {
+		name = "Bot"
+	}
+
+	seat, token, err := g.For(requestID(r)).JoinAs(name, "")
+	if err != nil {
+		s.writeAPIError(w, r, err)
+		return
+	}
+
+	writeJSON(w, status, api.JoinResponse{Game: apiGame(g, &seat), Seat: seat, Token: token})
+}
+
+// Runs action for the seat owning the request's token and responds with
+// the game from that seat's point of view.
+func (s *Server) apiSeatAction(w http.ResponseWriter, r *http.Request, action func(g *game.Game, seat int) error) {
+	g, err := s.game(r)
+	if err != nil {
+		s.writeAPIError(w, r, err)
+		return
+	}
+
+	seat, ok := seatFromBearer(r, g)
+	if !ok {
+		s.writeAPIError(w, r, errUnauthorized)
+		return
+	}
+
+	if err := action(g, seat); err != nil {
+		s.writeAPIError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, apiGame(g, &seat))
+}
+
+func apiGame(g *game.Game, seat *int) api.Game {
+	snapshot := g.Snapshot()
+
+	out := api.Game{
+		ID:        snapshot.ID,
+		Phase:     string(snapshot.Phase),
+		Turn:      snapshot.Turn,
+		Winner:    snapshot.Winner,
+		Forfeited: snapshot.Forfeited,
+		Players:   make([]api.Player, game.SEATS),
+		Moves:     apiMoves(snapshot.Moves),
+		Seat:      seat,
+	}
+
+	for i := range out.Players {
+		out.Players[i] = api.Player{
+			Seat:   i,
+			Name:   snapshot.Names[i],
+			Joined: snapshot.Joined[i],
+			Placed: snapshot.Placed[i],
+			Ready:  snapshot.Ready[i],
+		}
+
+		if snapshot.TimeControl.Clock > 0 {
+			clock := snapshot.Clocks[i].Milliseconds()
+			out.Players[i].ClockMs = &clock
+		}
+	}
+
+	if !snapshot.Deadline.IsZero() {
+		out.Deadline = &snapshot.Deadline
+	}
+
+	if seat != nil {
+		out.Fleet = []api.Coordinate{}
+		for i, c := range g.Fleet(*seat) {
+			if c.Occupied {
+				out.Fleet = append(out.Fleet, api.Coordinate{X: i % cell.BOARD_WIDTH, Y: i / cell.BOARD_WIDTH})
+			}
+		}
+	}
+
+	return out
+}
+
+func apiMoves(moves []game.Move) []api.Move {
+	out := make([]api.Move, len(moves))
+	for i, move := range moves {
+		out[i] = api.Move{
+			Seat:       move.Seat,
+			Hit:        move.Hit,
+			At:         move.At,
+			Coordinate: api.Coordinate{X: move.Coordinate[0], Y: move.Coordinate[1]},
+		}
+	}
+	return out
+}
+
+// Maps errors from the game engine onto a status code and error body.
+func apiError(err error) (int, api.Error) {
+	var code api.ErrorCode
+	var status int
+
+	switch {
+	case errors.Is(err, errInvalidRequest):
+		status, code = http.StatusBadRequest, api.INVALID_REQUEST
+	case errors.Is(err, cell.ErrOutOfBounds), errors.Is(err, cell.ErrOffBoard):
+		status, code = http.StatusBadRequest, api.INVALID_COORDINATE
+	case errors.Is(err, game.ErrInvalidFleet), errors.Is(err, player.ErrOccupied):
+		status, code = http.StatusBadRequest, api.INVALID_FLEET
+	case errors.Is(err, errUnauthorized), errors.Is(err, game.ErrUnknownSeat):
+		status, code = http.StatusUnauthorized, api.UNAUTHORIZED
+	case errors.Is(err, errGameNotFound):
+		status, code = http.StatusNotFound, api.NOT_FOUND
+	case errors.Is(err, game.ErrGameFull):
+		status, code = http.StatusConflict, api.GAME_FULL
+	case errors.Is(err, game.ErrWrongPhase), errors.Is(err, game.ErrAlreadyReady), errors.Is(err, game.ErrFleetNotReady):
+		status, code = http.StatusConflict, api.WRONG_PHASE
+	case errors.Is(err, game.ErrNotYourTurn):
+		status, code = http.StatusConflict, api.NOT_YOUR_TURN
+	case errors.Is(err, game.ErrAlreadyFired):
+		status, code = http.StatusConflict, api.ALREADY_FIRED
+	case errors.Is(err, storage.ErrNameTaken):
+		status, code = http.StatusConflict, api.NAME_TAKEN
+	case errors.Is(err, errRateLimited):
+		status, code = http.StatusTooManyRequests, api.RATE_LIMITED
+	case errors.Is(err, errBanned):
+		status, code = http.StatusForbidden, api.BANNED
+	case errors.Is(err, game.ErrSuspended):
+		status, code = http.StatusServiceUnavailable, api.UNAVAILABLE
+	default:
+		return http.StatusInternalServerError, api.Error{Code: api.INTERNAL, Message: "internal error"}
+	}
+
+	return status, api.Error{Code: code, Message: err.Error()}
+}
+
+func (s *Server) writeAPIError(w http.ResponseWriter, r *http.Request, err error) {
+	status, body := apiError(err)
+	if status == http.StatusInternalServerError {
+		s.logger(r).Error("Error handling API request", "error", err)
+	}
+	writeJSON(w, status, api.ErrorResponse{Error: body})
+}
+
+func readJSON(r *http.Request, v any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, MAX_API_BODY))
+	decoder.DisallowUnknownFields()
+	// An empty body is as good as an empty object
+	if err := decoder.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: %w", errInvalidRequest, err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func bearer(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, api.AUTH_SCHEME) {
+		return ""
+	}
+	return token
+}
+
+func seatFromBearer(r *http.Request, g *game.Game) (int, bool) {
+	return g.Seat(bearer(r))
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alfiehiscox/submarines/pkg/api"
+	"github.com/alfiehiscox/submarines/pkg/game"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{})
+	s.setUpRoutes()
+
+	ts := httptest.NewServer(s.mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// Sends body as JSON and decodes the response into out, returning the status code.
+func doJSON(t *testing.T, method, url, token string, body, out any) int {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("failed in set up: %s", err)
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		t.Fatalf("failed in set up: %s", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %s", err)
+	}
+	defer res.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+			t.Fatalf("failed to decode response: %s", err)
+		}
+	}
+
+	return res.StatusCode
+}
+
+func TestAPIGame(t *testing.T) {
+	ts := newTestServer(t)
+	base := ts.URL + "/api/v1/games"
+
+	var first, second api.JoinResponse
+	if status := doJSON(t, "POST", base, "", api.JoinRequest{Name: "first"}, &first); status != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", status)
+	}
+
+	games := base + "/" + first.Game.ID
+	if status := doJSON(t, "POST", games+"/join", "", api.JoinRequest{Name: "second"}, &second); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+
+	fleet := api.FleetRequest{Ships: []api.Ship{
+		{Size: 5, Orientation: "horizontal", Coordinate: api.Coordinate{X: 0, Y: 0}},
+		{Size: 4, Orientation: "horizontal", Coordinate: api.Coordinate{X: 0, Y: 1}},
+		{Size: 3, Orientation: "horizontal", Coordinate: api.Coordinate{X: 0, Y: 2}},
+		{Size: 3, Orientation: "horizontal", Coordinate: api.Coordinate{X: 0, Y: 3}},
+		{Size: 2, Orientation: "vertical", Coordinate: api.Coordinate{X: 9, Y: 8}},
+	}}
+
+	var state api.Game
+	if status := doJSON(t, "PUT", games+"/fleet", first.Token, fleet, &state); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if len(state.Fleet) != 17 {
+		t.Fatalf("expected 17 fleet cells, got %d", len(state.Fleet))
+	}
+
+	if status := doJSON(t, "PUT", games+"/fleet", second.Token, api.FleetRequest{Random: true}, nil); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+
+	for _, token := range []string{first.Token, second.Token} {
+		if status := doJSON(t, "POST", games+"/ready", token, nil, nil); status != http.StatusOK {
+			t.Fatalf("expected 200, got %d", status)
+		}
+	}
+
+	var apiErr api.ErrorResponse
+	shot := api.ShotRequest{Coordinate: api.Coordinate{X: 1, Y: 1}}
+	if status := doJSON(t, "POST", games+"/shots", second.Token, shot, &apiErr); status != http.StatusConflict || apiErr.Error.Code != api.NOT_YOUR_TURN {
+		t.Fatalf("expected 409 not_your_turn, got %d %s", status, apiErr.Error.Code)
+	}
+
+	off := api.ShotRequest{Coordinate: api.Coordinate{X: 10, Y: 1}}
+	if status := doJSON(t, "POST", games+"/shots", first.Token, off, &apiErr); status != http.StatusBadRequest || apiErr.Error.Code != api.INVALID_COORDINATE {
+		t.Fatalf("expected 400 invalid_coordinate, got %d %s", status, apiErr.Error.Code)
+	}
+
+	if status := doJSON(t, "POST", games+"/shots", "", shot, &apiErr); status != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", status)
+	}
+
+	if status := doJSON(t, "POST", games+"/shots", first.Token, shot, &state); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+
+	var moves api.MovesResponse
+	doJSON(t, "GET", games+"/moves?since=0", "", nil, &moves)
+	if len(moves.Moves) != 1 || moves.Moves[0].Coordinate != shot.Coordinate {
+		t.Fatalf("expected the one shot fired, got %v", moves.Moves)
+	}
+
+	var spectator api.Game
+	doJSON(t, "GET", games, "", nil, &spectator)
+	if spectator.Seat != nil || len(spectator.Fleet) != 0 {
+		t.Fatal("spectators must not see any fleet")
+	}
+}
+
+func TestAPIInvalidFleet(t *testing.T) {
+	ts := newTestServer(t)
+
+	var joined api.JoinResponse
+	doJSON(t, "POST", ts.URL+"/api/v1/games", "", nil, &joined)
+
+	fleet := api.FleetRequest{Ships: []api.Ship{
+		{Size: 5, Orientation: "horizontal", Coordinate: api.Coordinate{X: 0, Y: 0}},
+	}}
+
+	var apiErr api.ErrorResponse
+	status := doJSON(t, "PUT", ts.URL+"/api/v1/games/"+joined.Game.ID+"/fleet", joined.Token, fleet, &apiErr)
+	if status != http.StatusBadRequest || apiErr.Error.Code != api.INVALID_FLEET {
+		t.Fatalf("expected 400 invalid_fleet, got %d %s", status, apiErr.Error.Code)
+	}
+
+	status = doJSON(t, "GET", ts.URL+"/api/v1/games/missing", "", nil, &apiErr)
+	if status != http.StatusNotFound || apiErr.Error.Code != api.NOT_FOUND {
+		t.Fatalf("expected 404 not_found, got %d %s", status, apiErr.Error.Code)
+	}
+}
+
+func TestAPITimedGame(t *testing.T) {
+	tc := game.TimeControl{Clock: time.Minute, Increment: time.Second, OnTimeout: game.FORFEIT}
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{TimeControl: tc})
+	s.setUpRoutes()
+	ts := httptest.NewServer(s.mux)
+	defer ts.Close()
+
+	var first, second api.JoinResponse
+	doJSON(t, "POST", ts.URL+"/api/v1/games", "", api.JoinRequest{Name: "first"}, &first)
+	games := ts.URL + "/api/v1/games/" + first.Game.ID
+	doJSON(t, "POST", games+"/join", "", api.JoinRequest{Name: "second"}, &second)
+
+	for _, token := range []string{first.Token, second.Token} {
+		doJSON(t, "PUT", games+"/fleet", token, api.FleetRequest{Random: true}, nil)
+		doJSON(t, "POST", games+"/ready", token, nil, nil)
+	}
+
+	var state api.Game
+	doJSON(t, "GET", games, "", nil, &state)
+	if state.Deadline == nil || state.Players[1].ClockMs == nil || *state.Players[1].ClockMs != time.Minute.Milliseconds() {
+		t.Fatalf("expected a deadline and full clocks, got %+v", state)
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/alfiehiscox/submarines/pkg/cell"
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/alfiehiscox/submarines/pkg/html"
+	"github.com/alfiehiscox/submarines/pkg/storage"
+)
+
+func TestPlaceShips(t *testing.T) {
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{Repository: storage.NewMemory()})
+	s.setUpRoutes()
+	ts := httptest.NewServer(s.mux)
+	defer ts.Close()
+
+	b := newBrowser(t, ts.URL)
+	page := b.get("/place-ships")
+	for _, want := range []string{`role="grid"`, `role="row"`, `aria-label="A1"`, `aria-live="polite"`, html.Assets.URL("board.js")} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("expected the board to be an ARIA grid with %s", want)
+		}
+	}
+
+	// The gallery has each ship in the fleet, at its size
+	squares := 0
+	for _, size := range game.FLEET {
+		squares += size
+	}
+	if strings.Count(page, `name="ship"`) != len(game.FLEET) || strings.Count(page, "ship-square") != squares {
+		t.Fatalf("expected the gallery to match the fleet's %d ships", len(game.FLEET))
+	}
+
+	place := func(path string, form url.Values) (int, string) {
+		t.Helper()
+
+		req, _ := http.NewRequest("POST", ts.URL+path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("HX-Request", "true")
+		u, _ := url.Parse(ts.URL)
+		for _, cookie := range b.client.Jar.Cookies(u) {
+			if cookie.Name == CSRF_COOKIE {
+				req.Header.Set(CSRF_HEADER, cookie.Value)
+			}
+		}
+
+		res, err := b.client.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %s", err)
+		}
+		defer res.Body.Close()
+
+		body, _ := io.ReadAll(res.Body)
+		return res.StatusCode, string(body)
+	}
+
+	status, body := place("/place-ships/0/0", url.Values{"ship": {"1"}, "orientation": {"HORIZONTAL"}})
+	if status != http.StatusOK {
+		t.Fatalf("expected the carrier to be placed, got %d: %s", status, body)
+	}
+	for _, want := range []string{
+		`aria-label="E1, ship"`,
+		`name="ship1" value="0,0,HORIZONTAL"`,
+		"Carrier placed at A1, Across",
+		`value="2" class="sr-only" aria-label="Battleship, 4 squares" checked`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %s once the carrier is placed, got %s", want, body)
+		}
+	}
+
+	for _, tc := range []struct {
+		name string
+		path string
+		form url.Values
+	}{
+		{"overlapping", "/place-ships/2/0", url.Values{"ship": {"2"}, "orientation": {"VERTICAL"}, "ship1": {"0,0,HORIZONTAL"}}},
+		{"off the board", "/place-ships/8/0", url.Values{"ship": {"1"}, "orientation": {"HORIZONTAL"}}},
+		{"no ship chosen", "/place-ships/0/0", url.Values{"orientation": {"HORIZONTAL"}}},
+		{"no orientation", "/place-ships/0/0", url.Values{"ship": {"1"}}},
+		{"placed ship below the board", "/place-ships/0/0", url.Values{"ship": {"1"}, "orientation": {"HORIZONTAL"}, "ship5": {"10,8,VERTICAL"}}},
+		{"placed ship past the edge", "/place-ships/0/0", url.Values{"ship": {"1"}, "orientation": {"HORIZONTAL"}, "ship5": {"10,0,VERTICAL"}}},
+		{"placed ship before the edge", "/place-ships/0/0", url.Values{"ship": {"1"}, "orientation": {"HORIZONTAL"}, "ship5": {"-1,0,VERTICAL"}}},
+	} {
+		if status, _ := place(tc.path, tc.form); status != http.StatusBadRequest {
+			t.Fatalf("%s: expected a bad request, got %d", tc.name, status)
+		}
+	}
+}
+
+func TestShotsAnnounced(t *testing.T) {
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{Repository: storage.NewMemory()})
+	s.setUpRoutes()
+	ts := httptest.NewServer(s.mux)
+	defer ts.Close()
+
+	b := newBrowser(t, ts.URL)
+	b.get("/")
+
+	res := b.post("/games", nil, true)
+	path := res.Header.Get("HX-Redirect")
+	g, ok := s.games.Get(strings.TrimPrefix(path, "/games/"))
+	if !ok {
+		t.Fatalf("expected a redirect to the new game, got %q", path)
+	}
+	g.Join("opponent")
+	g.RandomizeFleet(0)
+	g.RandomizeFleet(1)
+	g.Ready(0)
+	g.Ready(1)
+
+	for i, c := range g.Fleet(1) {
+		if !c.Occupied {
+			coord := cell.Coordinate{i % cell.BOARD_WIDTH, i / cell.BOARD_WIDTH}
+			g.Fire(0, coord)
+
+			label := fmt.Sprintf("%c%d", 'A'+coord[0], coord[1]+1)
+			page := b.get(path)
+			if !strings.Contains(page, `aria-label="`+label+`, miss"`) {
+				t.Fatalf("expected %s to be labelled a miss", label)
+			}
+			if !strings.Contains(page, "You fired at "+label+": miss") {
+				t.Fatal("expected the shot to be announced")
+			}
+			if !strings.Contains(newBrowser(t, ts.URL).get(path+"/watch"), "fired at "+label+": miss") {
+				t.Fatal("expected the shot to be announced to spectators")
+			}
+			return
+		}
+	}
+}
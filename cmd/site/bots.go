@@ -0,0 +1,419 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alfiehiscox/submarines/pkg/account"
+	"github.com/alfiehiscox/submarines/pkg/api"
+	"github.com/alfiehiscox/submarines/pkg/cell"
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/alfiehiscox/submarines/pkg/storage"
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+// Messages a bot can send before we stop reading its connection
+const BOT_INBOX = 8
+
+var errNotInGame = errors.New("not in a game")
+
+// Parses bot keys given as "name:key,name:key" into a map of key to name.
+func ParseBotKeys(value string) (map[string]string, error) {
+	keys := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+
+		name, key, ok := strings.Cut(pair, ":")
+		if !ok || name == "" || key == "" {
+			return nil, errors.New("bot keys must look like name:key")
+		}
+		keys[key] = name
+	}
+	return keys, nil
+}
+
+// Returns the name of the bot owning the request's key.
+func (s *Server) authenticateBot(r *http.Request) (string, bool) {
+	given := bearer(r)
+	for key, name := range s.botKeys {
+		if subtle.ConstantTimeCompare([]byte(given), []byte(key)) == 1 {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// Returns the bot's account, creating it the first time it connects.
+func (s *Server) botAccount(name string) (account.Account, error) {
+	a := account.NewBot(name)
+	existing, err := s.repo.Account(a.ID)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return account.Account{}, err
+	}
+
+	if err := s.repo.SaveAccount(a); err != nil {
+		return account.Account{}, err
+	}
+	return a, nil
+}
+
+// Upgrades an authenticated bot to a websocket and plays games with it
+// until it disconnects.
+func (s *Server) BotGatewayHandler(w http.ResponseWriter, r *http.Request) {
+	name, ok := s.authenticateBot(r)
+	if !ok {
+		s.writeAPIError(w, r, errUnauthorized)
+		return
+	}
+
+	// Bots are rated on the same scale as everyone else
+	a, err := s.botAccount(name)
+	if err != nil {
+		s.writeAPIError(w, r, err)
+		return
+	}
+
+	// The socket lives far longer than the server's timeouts allow
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		s.logger(r).Error("Error accepting bot connection", "error", err)
+		return
+	}
+	defer conn.CloseNow()
+	defer s.metrics.session("bot")()
+
+	bot := &botSession{
+		server:  s,
+		name:    name,
+		account: a.ID,
+		conn:    conn,
+		inbox:   make(chan api.Message, BOT_INBOX),
+		request: requestID(r),
+		client:  r,
+		log:     s.logger(r),
+	}
+
+	bot.log.Info("Bot connected", "name", name)
+	err = bot.run(r.Context())
+	bot.log.Info("Bot disconnected", "name", name, "error", err)
+
+	conn.Close(websocket.StatusNormalClosure, "")
+}
+
+// The state of one bot connection. Only run's goroutine touches it.
+type botSession struct {
+	server  *Server
+	name    string
+	account string
+	conn    *websocket.Conn
+	inbox   chan api.Message
+
+	// ID of the request that opened the socket, which the bot's actions
+	// are taken on behalf of
+	request string
+	log     *slog.Logger
+
+	// The request that opened the socket, which the bot's shots are
+	// limited and struck against as the API's are
+	client *http.Request
+
+	ticket      *game.Ticket
+	game        *game.Game
+	seat        int
+	events      <-chan game.Event
+	unsubscribe func()
+	disconnect  func()
+	deadline    *time.Timer
+
+	// When the bot was last told it was its turn
+	prompted time.Time
+}
+
+func (b *botSession) run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer b.leave()
+
+	read := make(chan error, 1)
+	go func() {
+		for {
+			var msg api.Message
+			if err := wsjson.Read(ctx, b.conn, &msg); err != nil {
+				read <- err
+				return
+			}
+			select {
+			case b.inbox <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	if err := b.send(ctx, api.Message{Type: api.WELCOME, Name: b.name}); err != nil {
+		return err
+	}
+
+	for {
+		var matched <-chan game.Match
+		if b.ticket != nil {
+			matched = b.ticket.Matched()
+		}
+
+		var timeout <-chan time.Time
+		if b.deadline != nil {
+			timeout = b.deadline.C
+		}
+
+		var err error
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-read:
+			return err
+		case msg := <-b.inbox:
+			err = b.handle(ctx, msg)
+		case match := <-matched:
+			b.ticket = nil
+			err = b.start(ctx, match.Game, match.Seat)
+		case event, ok := <-b.events:
+			if !ok {
+				return errors.New("fell too far behind the game")
+			}
+			err = b.observe(ctx, event)
+		case <-timeout:
+			b.deadline = nil
+			err = b.game.Resign(b.seat)
+		}
+
+		if err != nil {
+			if err := b.sendError(ctx, err); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (b *botSession) handle(ctx context.Context, msg api.Message) error {
+	switch msg.Type {
+	case api.QUEUE:
+		if b.game != nil || b.ticket != nil {
+			return game.ErrWrongPhase
+		}
+
+		ticket, err := b.server.matchmaker.EnqueueAs(b.name, b.account)
+		if err != nil {
+			return err
+		}
+		b.ticket = ticket
+		return nil
+
+	case api.JOIN:
+		if b.game != nil || b.ticket != nil {
+			return game.ErrWrongPhase
+		}
+
+		g, ok := b.server.games.Get(msg.Game)
+		if !ok {
+			return errGameNotFound
+		}
+
+		seat, _, err := g.For(b.request).JoinAs(b.name, b.account)
+		if err != nil {
+			return err
+		}
+		return b.start(ctx, g, seat)
+
+	case api.PLACEMENT:
+		if b.game == nil {
+			return errNotInGame
+		}
+
+		if msg.Random {
+			if err := b.game.For(b.request).RandomizeFleet(b.seat); err != nil {
+				return err
+			}
+		} else {
+			ships := make([]game.Ship, len(msg.Ships))
+			for i, ship := range msg.Ships {
+				ships[i] = game.Ship{
+					Size:        ship.Size,
+					Orientation: cell.Orientation(strings.ToUpper(ship.Orientation)),
+					Coordinate:  cell.Coordinate{ship.X, ship.Y},
+				}
+			}
+			if err := b.game.For(b.request).PlaceFleet(b.seat, ships); err != nil {
+				return err
+			}
+		}
+
+		b.stopDeadline()
+		return b.game.For(b.request).Ready(b.seat)
+
+	case api.FIRE:
+		if b.game == nil {
+			return errNotInGame
+		}
+
+		if msg.Coordinate == nil {
+			return errInvalidRequest
+		}
+
+		if err := b.allowShot(); err != nil {
+			return err
+		}
+
+		_, err := b.game.For(b.request).Fire(b.seat, cell.Coordinate{msg.Coordinate.X, msg.Coordinate.Y})
+		b.server.strike(b.client, err)
+		if err != nil {
+			return err
+		}
+		b.server.metrics.botThink.Observe(time.Since(b.prompted).Seconds())
+		b.stopDeadline()
+		return nil
+
+	default:
+		return errInvalidRequest
+	}
+}
+
+// Takes a shot from the bot's SHOT_BUDGET, refusing it if that is spent
+// or the bot is banned.
+func (b *botSession) allowShot() error {
+	guard := b.server.guard
+	if guard == nil {
+		return nil
+	}
+
+	if _, banned := guard.Banned(b.client); banned {
+		return errBanned
+	}
+	if _, ok := guard.Allow(b.client, SHOT_BUDGET); !ok {
+		return errRateLimited
+	}
+	return nil
+}
+
+// Seats the bot in g and asks it for a fleet.
+func (b *botSession) start(ctx context.Context, g *game.Game, seat int) error {
+	b.game = g
+	b.seat = seat
+	b.events, b.unsubscribe = g.Subscribe(EVENT_BUFFER)
+	b.disconnect = g.Connect(seat)
+
+	snapshot := g.Snapshot()
+	matched := api.Message{Type: api.MATCHED, Game: g.ID, Seat: &seat, Name: snapshot.Names[1-seat]}
+	if err := b.send(ctx, matched); err != nil {
+		return err
+	}
+
+	deadline := b.startDeadline()
+	return b.send(ctx, api.Message{Type: api.PLACEMENT_REQUEST, Deadline: &deadline})
+}
+
+// Tells the bot about things happening in its game.
+func (b *botSession) observe(ctx context.Context, event game.Event) error {
+	switch event.Type {
+	case game.FIRED:
+		move := apiMoves([]game.Move{*event.Move})[0]
+		if err := b.send(ctx, api.Message{Type: api.SHOT_RESULT, Move: &move}); err != nil {
+			return err
+		}
+		return b.promptTurn(ctx)
+
+	case game.STARTED:
+		return b.promptTurn(ctx)
+
+	case game.SUSPENDED:
+		b.stopDeadline()
+		return game.ErrSuspended
+
+	case game.ENDED:
+		snapshot := b.game.Snapshot()
+		over := api.Message{Type: api.GAME_OVER, Game: b.game.ID, Seat: &snapshot.Winner, Forfeited: snapshot.Forfeited}
+		b.leave()
+		return b.send(ctx, over)
+	}
+
+	return nil
+}
+
+func (b *botSession) promptTurn(ctx context.Context) error {
+	snapshot := b.game.Snapshot()
+	if snapshot.Phase != game.PLAYING || snapshot.Turn != b.seat {
+		return nil
+	}
+
+	deadline := b.startDeadline()
+	b.prompted = time.Now()
+	return b.send(ctx, api.Message{Type: api.YOUR_TURN, Deadline: &deadline})
+}
+
+// Starts the clock on the bot's next move, returning when it runs out.
+func (b *botSession) startDeadline() time.Time {
+	b.stopDeadline()
+	b.deadline = time.NewTimer(b.server.botMoveTimeout)
+	return time.Now().Add(b.server.botMoveTimeout)
+}
+
+func (b *botSession) stopDeadline() {
+	if b.deadline != nil {
+		b.deadline.Stop()
+		b.deadline = nil
+	}
+}
+
+// Leaves the queue or the current game, ready to queue again. Resigns
+// the game if it is still going.
+func (b *botSession) leave() {
+	b.stopDeadline()
+
+	if b.ticket != nil {
+		b.server.matchmaker.Cancel(b.ticket)
+
+		// Matched but never told, so nobody is going to play the seat
+		select {
+		case match := <-b.ticket.Matched():
+			_ = match.Game.Resign(match.Seat)
+		default:
+		}
+
+		b.ticket = nil
+	}
+
+	if b.game != nil {
+		// Bots can't resume, so there is no point waiting for them
+		_ = b.game.For(b.request).Resign(b.seat)
+		b.unsubscribe()
+		b.disconnect()
+		b.game = nil
+		b.events = nil
+	}
+}
+
+func (b *botSession) send(ctx context.Context, msg api.Message) error {
+	return wsjson.Write(ctx, b.conn, msg)
+}
+
+func (b *botSession) sendError(ctx context.Context, err error) error {
+	status, body := apiError(err)
+	if status == http.StatusInternalServerError {
+		b.log.Error("Error handling bot message", "name", b.name, "error", err)
+	}
+	return b.send(ctx, api.Message{Type: api.ERROR, Error: &body})
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alfiehiscox/submarines/pkg/api"
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+func newBotServer(t *testing.T, moveTimeout time.Duration) *httptest.Server {
+	t.Helper()
+
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{
+		BotMoveTimeout: moveTimeout,
+		BotKeys:        map[string]string{"key-a": "alpha", "key-b": "bravo"},
+	})
+	s.setUpRoutes()
+
+	ts := httptest.NewServer(s.mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func dialBot(t *testing.T, ctx context.Context, ts *httptest.Server, key string) *websocket.Conn {
+	t.Helper()
+
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/api/v1/bots"
+	conn, _, err := websocket.Dial(ctx, url, &websocket.DialOptions{
+		HTTPHeader: http.Header{"Authorization": []string{"Bearer " + key}},
+	})
+	if err != nil {
+		t.Fatalf("failed to dial: %s", err)
+	}
+	t.Cleanup(func() { conn.CloseNow() })
+
+	return conn
+}
+
+// Reads messages until one of type arrives.
+func expect(t *testing.T, ctx context.Context, conn *websocket.Conn, typ api.MessageType) api.Message {
+	t.Helper()
+
+	for {
+		var msg api.Message
+		if err := wsjson.Read(ctx, conn, &msg); err != nil {
+			t.Fatalf("waiting for %s: %s", typ, err)
+		}
+		if msg.Type == typ {
+			return msg
+		}
+		if msg.Type == api.ERROR {
+			t.Fatalf("waiting for %s: got error %v", typ, msg.Error)
+		}
+	}
+}
+
+func TestBotGatewayRejectsUnknownKeys(t *testing.T) {
+	ts := newBotServer(t, time.Second)
+
+	_, res, err := websocket.Dial(context.Background(), "ws"+strings.TrimPrefix(ts.URL, "http")+"/api/v1/bots", nil)
+	if err == nil || res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %v", err)
+	}
+}
+
+func TestBotsPlayEachOther(t *testing.T) {
+	ts := newBotServer(t, 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	bots := []*websocket.Conn{dialBot(t, ctx, ts, "key-a"), dialBot(t, ctx, ts, "key-b")}
+
+	seats := make(map[int]*websocket.Conn)
+	for _, bot := range bots {
+		expect(t, ctx, bot, api.WELCOME)
+		wsjson.Write(ctx, bot, api.Message{Type: api.QUEUE})
+	}
+
+	for _, bot := range bots {
+		matched := expect(t, ctx, bot, api.MATCHED)
+		seats[*matched.Seat] = bot
+
+		expect(t, ctx, bot, api.PLACEMENT_REQUEST)
+		wsjson.Write(ctx, bot, api.Message{Type: api.PLACEMENT, Random: true})
+	}
+
+	// Each bot sweeps the board in order when it is their turn
+	shots := make(map[int]int)
+	for turn := 0; ; turn = 1 - turn {
+		bot := seats[turn]
+
+		var msg api.Message
+		for msg.Type != api.YOUR_TURN && msg.Type != api.GAME_OVER {
+			if err := wsjson.Read(ctx, bot, &msg); err != nil {
+				t.Fatalf("waiting for turn: %s", err)
+			}
+		}
+
+		if msg.Type == api.GAME_OVER {
+			if *msg.Seat < 0 || msg.Forfeited {
+				t.Fatalf("expected a winner without forfeit, got %v", msg)
+			}
+			return
+		}
+
+		n := shots[turn]
+		shots[turn]++
+		wsjson.Write(ctx, bot, api.Message{Type: api.FIRE, Coordinate: &api.Coordinate{X: n % 10, Y: n / 10}})
+	}
+}
+
+func TestBotForfeitsOnTimeout(t *testing.T) {
+	ts := newBotServer(t, 50*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	bots := []*websocket.Conn{dialBot(t, ctx, ts, "key-a"), dialBot(t, ctx, ts, "key-b")}
+	for _, bot := range bots {
+		expect(t, ctx, bot, api.WELCOME)
+		wsjson.Write(ctx, bot, api.Message{Type: api.QUEUE})
+	}
+
+	// Only the first bot places its fleet
+	expect(t, ctx, bots[0], api.PLACEMENT_REQUEST)
+	wsjson.Write(ctx, bots[0], api.Message{Type: api.PLACEMENT, Random: true})
+
+	over := expect(t, ctx, bots[0], api.GAME_OVER)
+	lazy := expect(t, ctx, bots[1], api.MATCHED)
+	if !over.Forfeited || *over.Seat == *lazy.Seat {
+		t.Fatalf("expected the idle bot to forfeit, got %v", over)
+	}
+}
+
+func TestBotShotsLimited(t *testing.T) {
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{
+		RateLimit:      true,
+		BotMoveTimeout: 5 * time.Second,
+		BotKeys:        map[string]string{"key-a": "alpha", "key-b": "bravo"},
+	})
+	s.setUpRoutes()
+	ts := httptest.NewServer(s.mux)
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	bots := []*websocket.Conn{dialBot(t, ctx, ts, "key-a"), dialBot(t, ctx, ts, "key-b")}
+	seats := make(map[int]*websocket.Conn)
+	for _, bot := range bots {
+		expect(t, ctx, bot, api.WELCOME)
+		wsjson.Write(ctx, bot, api.Message{Type: api.QUEUE})
+	}
+	for _, bot := range bots {
+		matched := expect(t, ctx, bot, api.MATCHED)
+		seats[*matched.Seat] = bot
+
+		expect(t, ctx, bot, api.PLACEMENT_REQUEST)
+		wsjson.Write(ctx, bot, api.Message{Type: api.PLACEMENT, Random: true})
+	}
+	expect(t, ctx, seats[0], api.YOUR_TURN)
+
+	// Firing out of turn counts against the bot until it is banned
+	waiting := seats[1]
+	fire := func() api.ErrorCode {
+		t.Helper()
+
+		wsjson.Write(ctx, waiting, api.Message{Type: api.FIRE, Coordinate: &api.Coordinate{X: 0, Y: 0}})
+		for {
+			var msg api.Message
+			if err := wsjson.Read(ctx, waiting, &msg); err != nil {
+				t.Fatalf("waiting for an error: %s", err)
+			}
+			if msg.Type == api.ERROR {
+				return msg.Error.Code
+			}
+		}
+	}
+
+	for i := 0; i < BAN_STRIKES; i++ {
+		if code := fire(); code != api.NOT_YOUR_TURN {
+			t.Fatalf("expected an out of turn shot to be refused, got %s", code)
+		}
+	}
+	if code := fire(); code != api.BANNED {
+		t.Fatalf("expected the bot to be banned, got %s", code)
+	}
+}
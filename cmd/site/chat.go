@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/alfiehiscox/submarines/pkg/html"
+	. "maragu.dev/gomponents"
+)
+
+func (s *Server) ChatHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
+	text := r.FormValue("text")
+	return s.chatAction(w, r, func(g *game.Game, seat int) error {
+		_, err := g.For(requestID(r)).Say(seat, text)
+		return err
+	})
+}
+
+func (s *Server) MuteHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
+	muted := r.URL.Query().Get("muted") == "true"
+	return s.chatAction(w, r, func(g *game.Game, seat int) error {
+		return g.Mute(seat, muted)
+	})
+}
+
+// Reports the opponent's messages. Reports are kept with the game and
+// logged for a moderator to follow up.
+func (s *Server) ReportHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
+	return s.chatAction(w, r, func(g *game.Game, seat int) error {
+		if g.Snapshot().Reported[seat] {
+			return nil
+		}
+
+		if err := g.For(requestID(r)).Report(seat); err != nil {
+			return err
+		}
+
+		s.logger(r).Warn("Chat reported", "game", g.ID, "seat", seat)
+		return nil
+	})
+}
+
+// Runs action for the requesting player's seat and renders their chat,
+// showing why if the action was refused.
+func (s *Server) chatAction(w http.ResponseWriter, r *http.Request, action func(g *game.Game, seat int) error) (Node, error) {
+	g, err := s.game(r)
+	if err != nil {
+		return nil, err
+	}
+
+	seat, ok := seatFromCookie(r, g)
+	if !ok {
+		return nil, errNotSeated
+	}
+
+	problem := ""
+	if err := action(g, seat); err != nil {
+		if !isChatProblem(err) {
+			return nil, err
+		}
+		problem = s.problem(r, err)
+	}
+
+	return html.ChatBox(locale(r), g.Snapshot(), seat, problem), nil
+}
+
+// Whether err is down to what the player sent, rather than something
+// going wrong.
+func isChatProblem(err error) bool {
+	for _, problem := range []error{game.ErrEmptyMessage, game.ErrMessageTooLong, game.ErrChatTooFast, game.ErrChatClosed, game.ErrSuspended} {
+		if errors.Is(err, problem) {
+			return true
+		}
+	}
+	return false
+}
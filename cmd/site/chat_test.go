@@ -0,0 +1,53 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/alfiehiscox/submarines/pkg/chat"
+)
+
+func TestChat(t *testing.T) {
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{ChatFilter: chat.NewFilter([]string{"darn"})})
+	s.setUpRoutes()
+	ts := httptest.NewServer(s.mux)
+	defer ts.Close()
+
+	b := newBrowser(t, ts.URL)
+	b.get("/")
+
+	res := b.post("/games", nil, true)
+	path := res.Header.Get("HX-Redirect")
+	g, ok := s.games.Get(strings.TrimPrefix(path, "/games/"))
+	if !ok {
+		t.Fatalf("expected a redirect to the new game, got %q", path)
+	}
+	g.Join("opponent")
+
+	b.post(path+"/chat", url.Values{"text": {"darn, good luck"}}, true)
+	b.post(path+"/chat/report", nil, true)
+
+	if page := b.get(path); !strings.Contains(page, "****, good luck") || !strings.Contains(page, "Reported") {
+		t.Fatal("expected the filtered message and the report on the page")
+	}
+
+	b.post(path+"/chat/mute?muted=true", nil, true)
+	g.Say(1, "hello")
+
+	if page := b.get(path); strings.Contains(page, "hello") || !strings.Contains(page, "opponent is muted") {
+		t.Fatal("expected the opponent's messages to be hidden")
+	}
+
+	// Spectators see everything said
+	if page := newBrowser(t, ts.URL).get(path + "/watch"); !strings.Contains(page, "hello") {
+		t.Fatal("expected spectators to see the chat")
+	}
+
+	if record := g.Record(); len(record.Chat) != 2 || len(record.Reports) != 1 {
+		t.Fatalf("expected the chat and report in the record, got %v %v", record.Chat, record.Reports)
+	}
+}
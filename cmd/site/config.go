@@ -0,0 +1,270 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alfiehiscox/submarines/pkg/game"
+)
+
+// Prefix of the environment variables settings can be given in
+const ENV_PREFIX = "SUBMARINES_"
+
+// Config is every setting the server reads at startup. Each setting is a
+// flag, and can also be given in the environment as ENV_PREFIX plus the
+// flag's name in upper snake case, or in a JSON config file keyed by
+// flag name. Flags win over the environment, which wins over the file.
+type Config struct {
+	// Optional JSON file to read settings from
+	File string
+
+	Addr              string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+
+	// Serves HTTPS when both are set
+	TLSCert string
+	TLSKey  string
+
+	// Serves /static from here rather than the files built in, empty
+	// for those
+	StaticDir string
+
+	LogLevel  slog.Level
+	LogFormat string
+
+	// Where games and accounts are saved, empty to keep them in memory
+	DB string
+
+	// File of words to mask in chat, empty to allow anything
+	ChatFilter string
+
+	RateLimit bool
+
+	ForfeitTimeout time.Duration
+	BotMoveTimeout time.Duration
+	MoveTime       time.Duration
+	Clock          time.Duration
+	Increment      time.Duration
+	OnTimeout      string
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Addr:              ":8080",
+		ReadTimeout:       10 * time.Second,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      10 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+		LogLevel:          slog.LevelInfo,
+		LogFormat:         "text",
+		DB:                "submarines.db",
+		RateLimit:         true,
+		ForfeitTimeout:    2 * time.Minute,
+		BotMoveTimeout:    10 * time.Second,
+		MoveTime:          time.Minute,
+		OnTimeout:         "random-shot",
+	}
+}
+
+// Reads the config from the command line arguments, the environment and
+// the config file, then checks it makes sense. Returns flag.ErrHelp if
+// asked for usage, which has already been printed.
+func LoadConfig(args []string, getenv func(string) string) (Config, error) {
+	// Parsed once just to find the config file, which the flags then
+	// have to be applied on top of
+	cfg := DefaultConfig()
+	if err := cfg.flags(io.Discard).Parse(args); err != nil && !errors.Is(err, flag.ErrHelp) {
+		return cfg, err
+	}
+
+	file := cfg.File
+	if file == "" {
+		file = getenv(envName("config"))
+	}
+
+	cfg = DefaultConfig()
+	fs := cfg.flags(os.Stderr)
+
+	if file != "" {
+		if err := applyFile(fs, file); err != nil {
+			return cfg, err
+		}
+	}
+
+	var errs []error
+	fs.VisitAll(func(f *flag.Flag) {
+		if value := getenv(envName(f.Name)); value != "" {
+			if err := fs.Set(f.Name, value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", envName(f.Name), err))
+			}
+		}
+	})
+	if err := errors.Join(errs...); err != nil {
+		return cfg, err
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	return cfg, cfg.Validate()
+}
+
+func (cfg *Config) flags(output io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("site", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.Usage = func() {
+		fmt.Fprintf(output, "Usage of site:\n\nEvery flag can also be set in the environment, such as %s for -addr, or in the -config file.\n\n", envName("addr"))
+		fs.PrintDefaults()
+	}
+
+	fs.StringVar(&cfg.File, "config", cfg.File, "JSON file of settings keyed by flag name")
+
+	fs.StringVar(&cfg.Addr, "addr", cfg.Addr, "address to listen on")
+	fs.DurationVar(&cfg.ReadTimeout, "read-timeout", cfg.ReadTimeout, "how long to wait for a request, 0 for no limit")
+	fs.DurationVar(&cfg.ReadHeaderTimeout, "read-header-timeout", cfg.ReadHeaderTimeout, "how long to wait for a request's headers, 0 for no limit")
+	fs.DurationVar(&cfg.WriteTimeout, "write-timeout", cfg.WriteTimeout, "how long to take writing a response, 0 for no limit. Event streams and sockets are exempt")
+	fs.DurationVar(&cfg.IdleTimeout, "idle-timeout", cfg.IdleTimeout, "how long to keep idle connections open")
+	fs.StringVar(&cfg.TLSCert, "tls-cert", cfg.TLSCert, "certificate file to serve HTTPS with, along with -tls-key")
+	fs.StringVar(&cfg.TLSKey, "tls-key", cfg.TLSKey, "private key file to serve HTTPS with, along with -tls-cert")
+
+	fs.StringVar(&cfg.StaticDir, "static-dir", cfg.StaticDir, "directory to serve /static from instead of the files built in, such as while working on the stylesheet")
+	fs.TextVar(&cfg.LogLevel, "log-level", cfg.LogLevel, "lowest level to log: debug, info, warn or error")
+	fs.StringVar(&cfg.LogFormat, "log-format", cfg.LogFormat, "how to write logs: text or json")
+
+	fs.StringVar(&cfg.DB, "db", cfg.DB, "file to store games in, empty to keep them in memory")
+	fs.StringVar(&cfg.ChatFilter, "chat-filter", cfg.ChatFilter, "file of words to mask in chat, one per line")
+	fs.BoolVar(&cfg.RateLimit, "rate-limit", cfg.RateLimit, "limit how quickly each client can make requests, and ban those making many bad moves for a while")
+
+	fs.DurationVar(&cfg.ForfeitTimeout, "forfeit-timeout", cfg.ForfeitTimeout, "how long a disconnected player has to return before forfeiting, 0 to never forfeit")
+	fs.DurationVar(&cfg.BotMoveTimeout, "bot-move-timeout", cfg.BotMoveTimeout, "how long a bot has to make each move before forfeiting")
+	fs.DurationVar(&cfg.MoveTime, "move-time", cfg.MoveTime, "how long a player has to make each shot, 0 for no limit")
+	fs.DurationVar(&cfg.Clock, "clock", cfg.Clock, "how long a player has for all of their shots, 0 for no clock")
+	fs.DurationVar(&cfg.Increment, "increment", cfg.Increment, "time added to a player's clock after each shot")
+	fs.StringVar(&cfg.OnTimeout, "on-timeout", cfg.OnTimeout, "what happens when a player runs out of move time: random-shot or forfeit")
+
+	return fs
+}
+
+// Checks settings that parse but make no sense.
+func (cfg Config) Validate() error {
+	var errs []error
+
+	if cfg.Addr == "" {
+		errs = append(errs, errors.New("addr is required"))
+	}
+
+	durations := map[string]time.Duration{
+		"read-timeout":        cfg.ReadTimeout,
+		"read-header-timeout": cfg.ReadHeaderTimeout,
+		"write-timeout":       cfg.WriteTimeout,
+		"idle-timeout":        cfg.IdleTimeout,
+		"forfeit-timeout":     cfg.ForfeitTimeout,
+		"move-time":           cfg.MoveTime,
+		"clock":               cfg.Clock,
+		"increment":           cfg.Increment,
+	}
+	for name, d := range durations {
+		if d < 0 {
+			errs = append(errs, fmt.Errorf("%s can't be negative", name))
+		}
+	}
+
+	if cfg.BotMoveTimeout <= 0 {
+		errs = append(errs, errors.New("bot-move-timeout must be positive"))
+	}
+
+	if (cfg.TLSCert == "") != (cfg.TLSKey == "") {
+		errs = append(errs, errors.New("tls-cert and tls-key must be set together"))
+	}
+
+	if cfg.StaticDir != "" {
+		if info, err := os.Stat(cfg.StaticDir); err != nil || !info.IsDir() {
+			errs = append(errs, fmt.Errorf("static-dir %q is not a directory", cfg.StaticDir))
+		}
+	}
+
+	if cfg.LogFormat != "text" && cfg.LogFormat != "json" {
+		errs = append(errs, fmt.Errorf("unknown log-format %q", cfg.LogFormat))
+	}
+
+	if _, err := parseTimeout(cfg.OnTimeout); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Returns the time control for new games.
+func (cfg Config) TimeControl() game.TimeControl {
+	timeout, _ := parseTimeout(cfg.OnTimeout)
+	return game.TimeControl{
+		MoveTime:  cfg.MoveTime,
+		Clock:     cfg.Clock,
+		Increment: cfg.Increment,
+		OnTimeout: timeout,
+	}
+}
+
+func (cfg Config) Logger() *slog.Logger {
+	opts := &slog.HandlerOptions{Level: cfg.LogLevel}
+	if cfg.LogFormat == "json" {
+		return slog.New(slog.NewJSONHandler(os.Stderr, opts))
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, opts))
+}
+
+// Sets the flags named in a JSON object. Values can be strings, numbers
+// or booleans, and durations are strings like "90s".
+func applyFile(fs *flag.FlagSet, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var settings map[string]any
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	var errs []error
+	for name, value := range settings {
+		if name == "config" || fs.Lookup(name) == nil {
+			errs = append(errs, fmt.Errorf("%s: unknown setting %q", path, name))
+			continue
+		}
+
+		if err := fs.Set(name, fmt.Sprint(value)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s: %w", path, name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Returns the environment variable for a flag, like SUBMARINES_MOVE_TIME
+// for -move-time.
+func envName(flag string) string {
+	return ENV_PREFIX + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+func parseTimeout(value string) (game.Timeout, error) {
+	switch value {
+	case "random-shot":
+		return game.RANDOM_SHOT, nil
+	case "forfeit":
+		return game.FORFEIT, nil
+	default:
+		return "", fmt.Errorf("unknown on-timeout %q", value)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alfiehiscox/submarines/pkg/game"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.json")
+	settings := `{"static-dir": "` + dir + `", "addr": ":1", "move-time": "30s", "clock": "5m", "log-format": "json"}`
+	if err := os.WriteFile(file, []byte(settings), 0600); err != nil {
+		t.Fatalf("failed in set up: %s", err)
+	}
+
+	env := map[string]string{
+		"SUBMARINES_CONFIG":     file,
+		"SUBMARINES_ADDR":       ":2",
+		"SUBMARINES_MOVE_TIME":  "20s",
+		"SUBMARINES_ON_TIMEOUT": "forfeit",
+	}
+
+	cfg, err := LoadConfig([]string{"-addr", ":3"}, func(key string) string { return env[key] })
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	if cfg.Addr != ":3" {
+		t.Fatalf("expected flags to win, got %q", cfg.Addr)
+	}
+
+	want := game.TimeControl{MoveTime: 20 * time.Second, Clock: 5 * time.Minute, OnTimeout: game.FORFEIT}
+	if cfg.TimeControl() != want || cfg.LogFormat != "json" {
+		t.Fatalf("expected the environment over the file, got %+v", cfg)
+	}
+
+	if cfg.WriteTimeout != DefaultConfig().WriteTimeout {
+		t.Fatalf("expected unset settings to keep their default, got %s", cfg.WriteTimeout)
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	noEnv := func(string) string { return "" }
+
+	_, err := LoadConfig([]string{"-static-dir", t.TempDir(), "-tls-cert", "cert.pem", "-on-timeout", "explode", "-clock", "-1s"}, noEnv)
+	for _, want := range []string{"tls-key", "on-timeout", "clock"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected an error about %s, got %v", want, err)
+		}
+	}
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(file, []byte(`{"colour": "blue"}`), 0600)
+	if _, err := LoadConfig([]string{"-config", file}, noEnv); err == nil || !strings.Contains(err.Error(), "colour") {
+		t.Fatalf("expected an unknown setting error, got %v", err)
+	}
+
+	if _, err := LoadConfig([]string{"-h"}, noEnv); !errors.Is(err, flag.ErrHelp) {
+		t.Fatalf("expected flag.ErrHelp, got %v", err)
+	}
+}
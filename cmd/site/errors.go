@@ -0,0 +1,184 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/alfiehiscox/submarines/pkg/account"
+	"github.com/alfiehiscox/submarines/pkg/cell"
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/alfiehiscox/submarines/pkg/html"
+	"github.com/alfiehiscox/submarines/pkg/i18n"
+	"github.com/alfiehiscox/submarines/pkg/player"
+	"github.com/alfiehiscox/submarines/pkg/storage"
+	ghttp "maragu.dev/gomponents/http"
+)
+
+const (
+	// Where htmx swaps error messages, see html.page
+	ERROR_TARGET = "#error"
+
+	// Catalog key of the message shown for errors that are down to the
+	// server rather than the user
+	SERVER_ERROR = "error.server"
+)
+
+var (
+	errNotSeated    = &httpError{status: http.StatusForbidden, key: "error.not_seated"}
+	errInvalidToken = &httpError{status: http.StatusNotFound, key: "error.invalid_token"}
+)
+
+// Catalog keys of the messages shown for errors from the game and the
+// packages around it, which the API reports in English as they are.
+var errorMessages = []struct {
+	err  error
+	key  string
+	args []any
+}{
+	{errInvalidRequest, "error.invalid_request", nil},
+	{errUnauthorized, "error.unauthorized", nil},
+	{errGameNotFound, "error.game_not_found", nil},
+	{cell.ErrOutOfBounds, "error.coordinate", nil},
+	{cell.ErrOffBoard, "error.off_board", nil},
+	{player.ErrOccupied, "error.occupied", nil},
+	{game.ErrInvalidFleet, "error.invalid_fleet", nil},
+	{game.ErrUnknownSeat, "error.unauthorized", nil},
+	{game.ErrGameFull, "error.game_full", nil},
+	{game.ErrWrongPhase, "error.wrong_phase", nil},
+	{game.ErrAlreadyReady, "error.already_ready", nil},
+	{game.ErrFleetNotReady, "error.fleet_not_ready", nil},
+	{game.ErrNotYourTurn, "error.not_your_turn", nil},
+	{game.ErrAlreadyFired, "error.already_fired", nil},
+	{game.ErrSuspended, "error.suspended", nil},
+	{errRateLimited, "error.rate_limited", nil},
+	{errBanned, "error.banned", nil},
+	{game.ErrEmptyMessage, "chat.empty_message", nil},
+	{game.ErrMessageTooLong, "chat.too_long", []any{game.MAX_MESSAGE}},
+	{game.ErrChatTooFast, "chat.too_fast", nil},
+	{game.ErrChatClosed, "chat.closed", nil},
+	{account.ErrInvalidName, "account.invalid_name", nil},
+	{account.ErrInvalidPassword, "account.invalid_password", []any{account.MIN_PASSWORD, account.MAX_PASSWORD}},
+	{account.ErrWrongPassword, "account.wrong_password", nil},
+	{account.ErrRegistered, "account.registered", nil},
+	{storage.ErrNameTaken, "account.name_taken", nil},
+}
+
+// An error fit to show the user, responded to with its status and the
+// message under key in the user's language. The underlying error, if
+// any, is logged but never shown.
+type httpError struct {
+	status int
+	key    string
+	args   []any
+	err    error
+}
+
+func badRequest(key string, err error) error {
+	return &httpError{status: http.StatusBadRequest, key: key, err: err}
+}
+
+func notFound(key string) error {
+	return &httpError{status: http.StatusNotFound, key: key}
+}
+
+// In English, for logs
+func (e *httpError) Error() string {
+	message := i18n.Locale{}.T(e.key, e.args...)
+	if e.err != nil {
+		return message + ": " + e.err.Error()
+	}
+	return message
+}
+
+func (e *httpError) Unwrap() error {
+	return e.err
+}
+
+// Satisfies gomponents' http package too
+func (e *httpError) StatusCode() int {
+	return e.status
+}
+
+// Like ghttp.Adapt, but responds to errors with the right status and a
+// message the user can act on: a page of its own when navigating, or
+// swapped into the page for htmx requests.
+func (s *Server) adapt(h ghttp.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		node, err := h(w, r)
+		if err != nil {
+			status, message := errorStatus(locale(r), err)
+			if status >= http.StatusInternalServerError {
+				s.logger(r).Error("Error handling request", "error", err)
+			}
+			renderError(w, r, status, message)
+			return
+		}
+
+		if node == nil {
+			return
+		}
+
+		if err := node.Render(w); err != nil {
+			s.logger(r).Error("Error rendering response", "error", err)
+		}
+	}
+}
+
+// Maps err onto a status and a message in l fit to show the user. Errors
+// from the game engine map as they do in the API, and any other error
+// is the server's.
+func errorStatus(l i18n.Locale, err error) (int, string) {
+	var herr *httpError
+	if errors.As(err, &herr) {
+		return herr.status, l.T(herr.key, herr.args...)
+	}
+
+	status, _ := apiError(err)
+	message, ok := errorMessage(l, err)
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	return status, message
+}
+
+// Returns the message in l for err, which is one the user can do
+// something about such as a game or account error, and whether it has
+// one. Other errors get SERVER_ERROR's, as what they say is for the
+// logs rather than the user.
+func errorMessage(l i18n.Locale, err error) (string, bool) {
+	for _, m := range errorMessages {
+		if errors.Is(err, m.err) {
+			return l.T(m.key, m.args...), true
+		}
+	}
+	return l.T(SERVER_ERROR), false
+}
+
+// Returns the message to show in a form for err, logging err if it
+// isn't one the user can do anything about.
+func (s *Server) problem(r *http.Request, err error) string {
+	message, ok := errorMessage(locale(r), err)
+	if !ok {
+		s.logger(r).Error("Error without a message for the user", "error", err)
+	}
+	return message
+}
+
+// Renders message as the response to r, with status.
+func renderError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	l := locale(r)
+
+	// htmx leaves the page alone on errors unless told where they go,
+	// see static/errors.js
+	if isFragmentRequest(r) {
+		w.Header().Set("HX-Retarget", ERROR_TARGET)
+		w.Header().Set("HX-Reswap", "innerHTML")
+		w.WriteHeader(status)
+		_ = html.ErrorMessage(l, message, requestID(r)).Render(w)
+		return
+	}
+
+	w.WriteHeader(status)
+	_ = html.ErrorPage(l, l.Status(status), message, requestID(r)).Render(w)
+}
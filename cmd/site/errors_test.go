@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alfiehiscox/submarines/pkg/account"
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/alfiehiscox/submarines/pkg/i18n"
+)
+
+func TestErrorPages(t *testing.T) {
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{})
+	s.setUpRoutes()
+	ts := httptest.NewServer(s.mux)
+	defer ts.Close()
+
+	for _, tc := range []struct {
+		path    string
+		status  int
+		message string
+	}{
+		{"/ship-select/first", http.StatusBadRequest, "ship id must be a number"},
+		{"/ship-select/9", http.StatusNotFound, "ship id unavailable"},
+		{"/games/missing", http.StatusNotFound, "game not found"},
+		{"/games/missing/watch?delay=soon", http.StatusNotFound, "game not found"},
+		{"/players/nobody", http.StatusNotFound, "player not found"},
+	} {
+		res, err := http.Get(ts.URL + tc.path)
+		if err != nil {
+			t.Fatalf("request failed: %s", err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+
+		if res.StatusCode != tc.status {
+			t.Fatalf("%s: expected %d, got %d", tc.path, tc.status, res.StatusCode)
+		}
+
+		if page := string(body); !strings.Contains(page, "<html") || !strings.Contains(page, tc.message) {
+			t.Fatalf("%s: expected a page saying %q, got %s", tc.path, tc.message, page)
+		}
+	}
+}
+
+func TestErrorFragments(t *testing.T) {
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{})
+	s.setUpRoutes()
+	ts := httptest.NewServer(s.mux)
+	defer ts.Close()
+
+	b := newBrowser(t, ts.URL)
+	b.get("/")
+	path := b.post("/games", nil, true).Header.Get("HX-Redirect")
+
+	// Ships haven't been placed yet
+	res := b.post(path+"/fire/0/0", nil, true)
+	if res.StatusCode != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", res.StatusCode)
+	}
+
+	if target := res.Header.Get("HX-Retarget"); target != ERROR_TARGET {
+		t.Fatalf("expected the error to be retargeted, got %q", target)
+	}
+
+	// Someone else's game
+	res = newBrowser(t, ts.URL).post(path+"/ready", nil, false)
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", res.StatusCode)
+	}
+
+	req := httptest.NewRequest("POST", path+"/fire/0/0", nil)
+	req.Header.Set("HX-Request", "true")
+	w := httptest.NewRecorder()
+	renderError(w, req, http.StatusConflict, "not your turn")
+
+	if fragment := w.Body.String(); strings.Contains(fragment, "<html") || !strings.Contains(fragment, "not your turn") {
+		t.Fatalf("expected a fragment with the error, got %s", fragment)
+	}
+}
+
+func TestErrorMessagesTranslated(t *testing.T) {
+	es, _ := i18n.Lookup("es")
+	de, _ := i18n.Lookup("de")
+
+	for _, m := range errorMessages {
+		if message, ok := errorMessage(de, m.err); !ok || strings.Contains(message, m.key) || message == m.err.Error() {
+			t.Fatalf("expected %q to be translated, got %q", m.key, message)
+		}
+	}
+
+	for _, tc := range []struct {
+		l      i18n.Locale
+		err    error
+		status int
+		want   string
+	}{
+		{es, game.ErrNotYourTurn, http.StatusConflict, "no es tu turno"},
+		{de, badRequest("error.move", nil), http.StatusBadRequest, "der Zug muss eine Zahl sein"},
+		{es, errors.New("disk full"), http.StatusInternalServerError, "Lo sentimos, algo ha ido mal. Inténtalo de nuevo."},
+		{i18n.Locale{}, errPlayerNotFound, http.StatusNotFound, "player not found"},
+	} {
+		if status, message := errorStatus(tc.l, tc.err); status != tc.status || message != tc.want {
+			t.Fatalf("expected %d %q, got %d %q", tc.status, tc.want, status, message)
+		}
+	}
+
+	if message, _ := errorMessage(es, account.ErrInvalidPassword); message != "las contraseñas tienen de 8 a 72 caracteres" {
+		t.Fatalf("expected the password rules in Spanish, got %q", message)
+	}
+
+	// Errors without a message of their own never reach the user as they are
+	if message, ok := errorMessage(de, errors.New("disk full")); ok || message != de.T(SERVER_ERROR) {
+		t.Fatalf("expected the generic message for an unknown error, got %q", message)
+	}
+}
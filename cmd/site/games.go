@@ -0,0 +1,301 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alfiehiscox/submarines/pkg/cell"
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/alfiehiscox/submarines/pkg/html"
+	"github.com/alfiehiscox/submarines/pkg/i18n"
+	"github.com/alfiehiscox/submarines/pkg/storage"
+	"github.com/alfiehiscox/submarines/pkg/view"
+	"github.com/go-chi/chi/v5"
+	. "maragu.dev/gomponents"
+)
+
+const (
+	// Events a player's stream can buffer before it is dropped
+	EVENT_BUFFER = 16
+
+	// How often players and spectators are sent the clocks of a timed game
+	CLOCK_INTERVAL = time.Second
+)
+
+var errGameNotFound = errors.New("game not found")
+
+func (s *Server) NewGameHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
+	a, err := s.identify(w, r)
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := s.games.Create()
+	if err != nil {
+		return nil, err
+	}
+
+	_, token, err := g.For(requestID(r)).JoinAs(a.Name, a.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	setSeatCookie(w, g, token)
+	redirect(w, r, "/games/"+g.ID)
+	return nil, nil
+}
+
+func (s *Server) GameHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
+	g, err := s.game(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if seat, ok := seatFromCookie(r, g); ok {
+		token, _ := r.Cookie(seatCookieName(g))
+		resume := fmt.Sprintf("/games/%s/resume/%s", g.ID, token.Value)
+		return html.Game(locale(r), playerView(g, seat), resume), nil
+	}
+
+	snapshot := g.Snapshot()
+	if !snapshot.Joined[1] {
+		return html.JoinGame(locale(r), snapshot), nil
+	}
+
+	http.Redirect(w, r, "/games/"+g.ID+"/watch", http.StatusSeeOther)
+	return nil, nil
+}
+
+func (s *Server) JoinGameHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
+	g, err := s.game(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := seatFromCookie(r, g); !ok {
+		a, err := s.identify(w, r)
+		if err != nil {
+			return nil, err
+		}
+
+		_, token, err := g.For(requestID(r)).JoinAs(a.Name, a.ID)
+		if err != nil {
+			return nil, err
+		}
+		setSeatCookie(w, g, token)
+	}
+
+	redirect(w, r, "/games/"+g.ID)
+	return nil, nil
+}
+
+// Reclaims a seat from a new browser using the link shown to the player.
+func (s *Server) ResumeHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
+	g, err := s.game(r)
+	if err != nil {
+		return nil, err
+	}
+
+	token := chi.URLParam(r, "token")
+	if _, ok := g.Seat(token); !ok {
+		return nil, errInvalidToken
+	}
+
+	setSeatCookie(w, g, token)
+	redirect(w, r, "/games/"+g.ID)
+	return nil, nil
+}
+
+func (s *Server) ShuffleHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
+	return s.seatAction(w, r, func(g *game.Game, seat int) error {
+		return g.For(requestID(r)).RandomizeFleet(seat)
+	})
+}
+
+func (s *Server) ReadyHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
+	return s.seatAction(w, r, func(g *game.Game, seat int) error {
+		return g.For(requestID(r)).Ready(seat)
+	})
+}
+
+// Fires for the requesting player, counting bad moves against them.
+func (s *Server) FireHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
+	node, err := s.fire(w, r)
+	s.strike(r, err)
+	return node, err
+}
+
+func (s *Server) fire(w http.ResponseWriter, r *http.Request) (Node, error) {
+	x, err := strconv.Atoi(chi.URLParam(r, "x"))
+	if err != nil {
+		return nil, badRequest("error.coordinates", err)
+	}
+
+	y, err := strconv.Atoi(chi.URLParam(r, "y"))
+	if err != nil {
+		return nil, badRequest("error.coordinates", err)
+	}
+
+	coord, err := cell.NewCoordinate(x, y)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.seatAction(w, r, func(g *game.Game, seat int) error {
+		_, err := g.For(requestID(r)).Fire(seat, coord)
+		return err
+	})
+}
+
+// Streams the seated player's panel every time something happens, their
+// chat as messages arrive, and the clocks as they count down.
+func (s *Server) GameEventsHandler(w http.ResponseWriter, r *http.Request) {
+	g, err := s.game(r)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	seat, ok := seatFromCookie(r, g)
+	if !ok {
+		http.Error(w, "not seated in this game", http.StatusForbidden)
+		return
+	}
+
+	events, cancel := g.Subscribe(EVENT_BUFFER)
+	defer cancel()
+
+	// The open stream is how we know the player is still here
+	disconnect := g.Connect(seat)
+	defer disconnect()
+	defer s.metrics.session("player")()
+
+	stream, err := newEventStream(w)
+	if err != nil {
+		s.logger(r).Error("Error opening event stream", "error", err)
+		return
+	}
+
+	ticker := time.NewTicker(CLOCK_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+
+			if event.Type == game.CHAT {
+				if err := stream.Send("chat", html.ChatLog(locale(r), g.Snapshot(), seat)); err != nil {
+					return
+				}
+				continue
+			}
+
+			panel := html.GamePanel(locale(r), playerView(g, seat))
+			if err := stream.Send("game", panel); err != nil {
+				return
+			}
+
+			if event.Type == game.FIRED || event.Type == game.ENDED {
+				if err := stream.Send("shot", html.Announcement(locale(r), g.Snapshot(), seat)); err != nil {
+					return
+				}
+			}
+		case <-ticker.C:
+			if err := sendClocks(stream, locale(r), g.Snapshot()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Sends the clocks of a game whose turn is being timed.
+func sendClocks(stream *eventStream, l i18n.Locale, s game.Snapshot) error {
+	if s.Deadline.IsZero() {
+		return nil
+	}
+	return stream.Send("clock", html.Clocks(l, s))
+}
+
+// Runs action for the requesting player's seat and renders their panel.
+func (s *Server) seatAction(w http.ResponseWriter, r *http.Request, action func(g *game.Game, seat int) error) (Node, error) {
+	g, err := s.game(r)
+	if err != nil {
+		return nil, err
+	}
+
+	seat, ok := seatFromCookie(r, g)
+	if !ok {
+		return nil, errNotSeated
+	}
+
+	if err := action(g, seat); err != nil {
+		return nil, err
+	}
+
+	return html.GamePanel(locale(r), playerView(g, seat)), nil
+}
+
+// Returns what the player in seat can see of g.
+func playerView(g *game.Game, seat int) view.Player {
+	return view.ForPlayer(g.Snapshot(), seat, g.Fleet(seat))
+}
+
+// Returns the game in the URL. Finished games are no longer held in
+// memory, so are loaded from the repository, where nothing can change
+// them any more.
+func (s *Server) game(r *http.Request) (*game.Game, error) {
+	id := chi.URLParam(r, "id")
+	if g, ok := s.games.Get(id); ok {
+		return g, nil
+	}
+
+	record, err := s.repo.Game(id)
+	if errors.Is(err, storage.ErrNotFound) || (err == nil && record.Phase != game.FINISHED) {
+		return nil, errGameNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return game.Restore(record), nil
+}
+
+// Sends the browser to url. htmx would swap a plain redirect into the
+// page, so have it navigate instead.
+func redirect(w http.ResponseWriter, r *http.Request, url string) {
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Redirect", url)
+		return
+	}
+
+	http.Redirect(w, r, url, http.StatusSeeOther)
+}
+
+func seatCookieName(g *game.Game) string {
+	return "seat_" + g.ID
+}
+
+func setSeatCookie(w http.ResponseWriter, g *game.Game, token string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     seatCookieName(g),
+		Value:    token,
+		Path:     "/games/" + g.ID,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func seatFromCookie(r *http.Request, g *game.Game) (int, bool) {
+	cookie, err := r.Cookie(seatCookieName(g))
+	if err != nil {
+		return 0, false
+	}
+	return g.Seat(cookie.Value)
+}
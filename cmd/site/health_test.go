@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/alfiehiscox/submarines/pkg/storage"
+)
+
+func TestHealth(t *testing.T) {
+	repo, err := storage.OpenBolt(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed in set up: %s", err)
+	}
+
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{Repository: repo})
+	s.setUpRoutes()
+	ts := httptest.NewServer(s.mux)
+	defer ts.Close()
+
+	status := func(path string) int {
+		t.Helper()
+		res, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("request failed: %s", err)
+		}
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	if code := status("/healthz"); code != http.StatusOK {
+		t.Fatalf("expected healthz to be 200, got %d", code)
+	}
+	if code := status("/readyz"); code != http.StatusOK {
+		t.Fatalf("expected readyz to be 200, got %d", code)
+	}
+
+	repo.Close()
+	if code := status("/readyz"); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected readyz to be 503 without storage, got %d", code)
+	}
+	if code := status("/healthz"); code != http.StatusOK {
+		t.Fatalf("expected healthz to be 200 without storage, got %d", code)
+	}
+}
+
+func TestNotReadyWhileStopping(t *testing.T) {
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{})
+	s.setUpRoutes()
+
+	if err := s.Stop(); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	w := httptest.NewRecorder()
+	s.mux.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected readyz to be 503 once stopping, got %d", w.Code)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{})
+	s.setUpRoutes()
+
+	w := httptest.NewRecorder()
+	s.mux.ServeHTTP(w, httptest.NewRequest("GET", "/version", nil))
+
+	var info versionInfo
+	if err := json.NewDecoder(w.Body).Decode(&info); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if info.Version == "" || info.GoVersion == "" {
+		t.Fatalf("expected a version and go version, got %+v", info)
+	}
+}
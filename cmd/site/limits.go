@@ -0,0 +1,281 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alfiehiscox/submarines/pkg/account"
+	"github.com/alfiehiscox/submarines/pkg/api"
+	"github.com/alfiehiscox/submarines/pkg/cell"
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/alfiehiscox/submarines/pkg/i18n"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// Addresses can be shared by many people, such as an office behind
+	// one router, so they get this many sessions' worth of each budget
+	ADDRESS_FACTOR = 4
+
+	// Clients making BAN_STRIKES bad moves within STRIKE_WINDOW are
+	// refused everything for BAN_DURATION
+	BAN_STRIKES   = 10
+	STRIKE_WINDOW = time.Minute
+	BAN_DURATION  = 5 * time.Minute
+
+	// Clients are forgotten once they have been quiet this long, which
+	// refills their budgets anyway
+	CLIENT_IDLE = 10 * time.Minute
+)
+
+// Why bots, which have no status to be refused with, can't move
+var (
+	errRateLimited = errors.New("too many requests, slow down")
+	errBanned      = errors.New("too many invalid moves, try again later")
+)
+
+// How many requests of a kind a client can make: Rate a second on
+// average, in bursts of up to Burst.
+type Budget struct {
+	Name  string
+	Rate  rate.Limit
+	Burst int
+}
+
+var (
+	PAGE_BUDGET = Budget{Name: "page", Rate: 5, Burst: 30}
+	SHOT_BUDGET = Budget{Name: "shot", Rate: 2, Burst: 10}
+	CHAT_BUDGET = Budget{Name: "chat", Rate: 0.5, Burst: 5}
+)
+
+// Guard rate limits clients, each by its address and, when it has one,
+// its session, and bans those that keep making bad moves.
+type Guard struct {
+	mu      sync.Mutex
+	clients map[string]*visitor
+	swept   time.Time
+}
+
+type visitor struct {
+	limiters map[string]*rate.Limiter // by budget name
+	strikes  []time.Time
+	banned   time.Time // until
+	seen     time.Time
+}
+
+func NewGuard() *Guard {
+	return &Guard{clients: make(map[string]*visitor)}
+}
+
+// Returns middleware refusing requests beyond budget with 429 and a
+// Retry-After header, and any request from a banned client with 403.
+func (s *Server) limit(budget Budget) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if s.guard == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if until, banned := s.guard.Banned(r); banned {
+				w.Header().Set("Retry-After", retryAfter(time.Until(until)))
+				refuse(w, r, http.StatusForbidden, api.BANNED, "error.banned")
+				return
+			}
+
+			if wait, ok := s.guard.Allow(r, budget); !ok {
+				w.Header().Set("Retry-After", retryAfter(wait))
+				refuse(w, r, http.StatusTooManyRequests, api.RATE_LIMITED, "error.rate_limited")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Takes a request from each of r's client's budgets, returning how long
+// to wait if any of them are spent, in which case none are taken.
+func (g *Guard) Allow(r *http.Request, budget Budget) (time.Duration, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	g.sweep(now)
+
+	var reservations []*rate.Reservation
+	var wait time.Duration
+	for key, factor := range clientKeys(r) {
+		c := g.visitor(key, now)
+
+		limiter, ok := c.limiters[budget.Name]
+		if !ok {
+			limiter = rate.NewLimiter(budget.Rate*rate.Limit(factor), budget.Burst*factor)
+			c.limiters[budget.Name] = limiter
+		}
+
+		reservation := limiter.ReserveN(now, 1)
+		reservations = append(reservations, reservation)
+		wait = max(wait, reservation.DelayFrom(now))
+	}
+
+	if wait > 0 {
+		for _, reservation := range reservations {
+			reservation.CancelAt(now)
+		}
+		return wait, false
+	}
+
+	return 0, true
+}
+
+// Returns when r's client's ban ends, if it is banned.
+func (g *Guard) Banned(r *http.Request) (time.Time, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	var until time.Time
+	for key := range clientKeys(r) {
+		if c, ok := g.clients[key]; ok && c.banned.After(until) {
+			until = c.banned
+		}
+	}
+
+	return until, until.After(now)
+}
+
+// Counts err against r's client if it is a bad move, banning the client
+// once it has made too many. Strikes go against the client's session, so
+// one player can't get everyone sharing their address banned. Only
+// clients without a session are counted by address, which takes
+// ADDRESS_FACTOR times as many strikes to ban. Returns whether it was
+// banned.
+func (g *Guard) Strike(r *http.Request, err error) bool {
+	if !isBadMove(err) {
+		return false
+	}
+
+	key, factor := sessionKey(r), 1
+	if key == "" {
+		key, factor = addressKey(r), ADDRESS_FACTOR
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	c := g.visitor(key, now)
+
+	recent := c.strikes[:0]
+	for _, strike := range c.strikes {
+		if now.Sub(strike) < STRIKE_WINDOW {
+			recent = append(recent, strike)
+		}
+	}
+	c.strikes = append(recent, now)
+
+	if len(c.strikes) < BAN_STRIKES*factor {
+		return false
+	}
+
+	c.banned = now.Add(BAN_DURATION)
+	c.strikes = nil
+	return true
+}
+
+// Callers must hold g.mu.
+func (g *Guard) visitor(key string, now time.Time) *visitor {
+	c, ok := g.clients[key]
+	if !ok {
+		c = &visitor{limiters: make(map[string]*rate.Limiter)}
+		g.clients[key] = c
+	}
+	c.seen = now
+	return c
+}
+
+// Forgets quiet clients every so often. Callers must hold g.mu.
+func (g *Guard) sweep(now time.Time) {
+	if now.Sub(g.swept) < CLIENT_IDLE {
+		return
+	}
+	g.swept = now
+
+	for key, c := range g.clients {
+		if now.Sub(c.seen) >= CLIENT_IDLE && now.After(c.banned) {
+			delete(g.clients, key)
+		}
+	}
+}
+
+// Returns the keys r's client is known by, along with how many sessions'
+// worth of budget each gets. Addresses are taken from the connection, as
+// headers saying otherwise can be made up.
+func clientKeys(r *http.Request) map[string]int {
+	keys := map[string]int{addressKey(r): ADDRESS_FACTOR}
+	if key := sessionKey(r); key != "" {
+		keys[key] = 1
+	}
+	return keys
+}
+
+func addressKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "address:" + host
+}
+
+// Returns the key of r's session: its account or, for the API and bots,
+// its token. Empty if it has neither.
+func sessionKey(r *http.Request) string {
+	if a, ok := accountFrom(r); ok {
+		return "account:" + a.ID
+	}
+	if token := bearer(r); token != "" {
+		return "token:" + account.HashToken(token)
+	}
+	return ""
+}
+
+// Whether err is down to a client firing where or when it can't, which
+// players only do by accident now and then.
+func isBadMove(err error) bool {
+	for _, bad := range []error{game.ErrNotYourTurn, game.ErrAlreadyFired, cell.ErrOutOfBounds, strconv.ErrSyntax, strconv.ErrRange} {
+		if errors.Is(err, bad) {
+			return true
+		}
+	}
+	return false
+}
+
+// Responds in whichever format the route speaks, with the message under
+// key: in English for the API, otherwise in the user's language.
+func refuse(w http.ResponseWriter, r *http.Request, status int, code api.ErrorCode, key string) {
+	if strings.HasPrefix(r.URL.Path, "/api/") {
+		writeJSON(w, status, api.ErrorResponse{Error: api.Error{Code: code, Message: i18n.Locale{}.T(key)}})
+		return
+	}
+	renderError(w, r, status, locale(r).T(key))
+}
+
+// Formats wait in whole seconds, rounding up so clients don't come back
+// too early.
+func retryAfter(wait time.Duration) string {
+	return fmt.Sprint(int(math.Ceil(wait.Seconds())))
+}
+
+// Counts err against r's client, logging if that gets it banned.
+func (s *Server) strike(r *http.Request, err error) {
+	if s.guard != nil && s.guard.Strike(r, err) {
+		s.logger(r).Warn("Client banned for bad moves", "address", r.RemoteAddr, "duration", BAN_DURATION)
+	}
+}
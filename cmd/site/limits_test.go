@@ -0,0 +1,101 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alfiehiscox/submarines/pkg/api"
+	"github.com/alfiehiscox/submarines/pkg/game"
+)
+
+func newLimitedServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{RateLimit: true})
+	s.setUpRoutes()
+
+	ts := httptest.NewServer(s.mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestRateLimit(t *testing.T) {
+	ts := newLimitedServer(t)
+
+	var joined api.JoinResponse
+	doJSON(t, "POST", ts.URL+"/api/v1/games", "", api.JoinRequest{Name: "alpha"}, &joined)
+	game := ts.URL + "/api/v1/games/" + joined.Game.ID
+
+	for i := 0; i < PAGE_BUDGET.Burst; i++ {
+		if status := doJSON(t, "GET", game, joined.Token, nil, nil); status != http.StatusOK {
+			t.Fatalf("expected request %d to be allowed, got %d", i, status)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", game, nil)
+	req.Header.Set("Authorization", "Bearer "+joined.Token)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %s", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusTooManyRequests || res.Header.Get("Retry-After") == "" {
+		t.Fatalf("expected 429 with Retry-After, got %d %q", res.StatusCode, res.Header.Get("Retry-After"))
+	}
+
+	// Other sessions at the address have their own budget
+	if status := doJSON(t, "GET", game, "", nil, nil); status != http.StatusOK {
+		t.Fatalf("expected another session to be allowed, got %d", status)
+	}
+}
+
+func TestBanForBadMoves(t *testing.T) {
+	ts := newLimitedServer(t)
+
+	var first, second api.JoinResponse
+	doJSON(t, "POST", ts.URL+"/api/v1/games", "", api.JoinRequest{Name: "alpha"}, &first)
+	game := ts.URL + "/api/v1/games/" + first.Game.ID
+	doJSON(t, "POST", game+"/join", "", api.JoinRequest{Name: "beta"}, &second)
+	for _, token := range []string{first.Token, second.Token} {
+		doJSON(t, "PUT", game+"/fleet", token, api.FleetRequest{Random: true}, nil)
+		doJSON(t, "POST", game+"/ready", token, nil, nil)
+	}
+
+	shot := api.ShotRequest{Coordinate: api.Coordinate{X: 0, Y: 0}}
+	for i := 0; i < BAN_STRIKES; i++ {
+		if status := doJSON(t, "POST", game+"/shots", second.Token, shot, nil); status != http.StatusConflict {
+			t.Fatalf("expected an out of turn shot to be refused, got %d", status)
+		}
+	}
+
+	var refused api.ErrorResponse
+	if status := doJSON(t, "GET", game, second.Token, nil, &refused); status != http.StatusForbidden || refused.Error.Code != api.BANNED {
+		t.Fatalf("expected to be banned, got %d %s", status, refused.Error.Code)
+	}
+
+	// Others at the same address aren't
+	if status := doJSON(t, "GET", game, first.Token, nil, nil); status != http.StatusOK {
+		t.Fatalf("expected other sessions at the address to carry on, got %d", status)
+	}
+}
+
+func TestStrikesWithoutSession(t *testing.T) {
+	g := NewGuard()
+	r := httptest.NewRequest("POST", "/games/test/fire/0/0", nil)
+
+	for i := 1; i < BAN_STRIKES*ADDRESS_FACTOR; i++ {
+		if g.Strike(r, game.ErrNotYourTurn) {
+			t.Fatalf("expected an address to take %d strikes, banned after %d", BAN_STRIKES*ADDRESS_FACTOR, i)
+		}
+	}
+	if !g.Strike(r, game.ErrNotYourTurn) {
+		t.Fatal("expected the address to be banned")
+	}
+	if _, banned := g.Banned(r); !banned {
+		t.Fatal("expected requests from the address to be refused")
+	}
+}
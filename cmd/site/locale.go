@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/alfiehiscox/submarines/pkg/html"
+	"github.com/alfiehiscox/submarines/pkg/i18n"
+)
+
+const (
+	// Remembers the language a user picked over their browser's
+	LANGUAGE_COOKIE     = "lang"
+	LANGUAGE_COOKIE_AGE = 365 * 24 * time.Hour
+)
+
+type localeKey struct{}
+
+// Picks the language to respond in: the one last chosen with the
+// html.LANGUAGE_PARAM query parameter, which is remembered in a cookie,
+// otherwise the best match for the browser's Accept-Language.
+func localize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		override := ""
+		if cookie, err := r.Cookie(LANGUAGE_COOKIE); err == nil {
+			override = cookie.Value
+		}
+
+		if l, ok := i18n.Lookup(r.URL.Query().Get(html.LANGUAGE_PARAM)); ok {
+			override = l.Tag()
+			http.SetCookie(w, &http.Cookie{
+				Name:     LANGUAGE_COOKIE,
+				Value:    override,
+				Path:     "/",
+				MaxAge:   int(LANGUAGE_COOKIE_AGE.Seconds()),
+				HttpOnly: true,
+				Secure:   r.TLS != nil,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+
+		l := i18n.Negotiate(override, r.Header.Get("Accept-Language"))
+		w.Header().Set("Content-Language", l.Tag())
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), localeKey{}, l)))
+	})
+}
+
+// Returns the locale picked for r by localize, English if there isn't one.
+func locale(r *http.Request) i18n.Locale {
+	l, _ := r.Context().Value(localeKey{}).(i18n.Locale)
+	return l
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alfiehiscox/submarines/pkg/storage"
+)
+
+func TestLocalize(t *testing.T) {
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{Repository: storage.NewMemory()})
+	s.setUpRoutes()
+	ts := httptest.NewServer(s.mux)
+	defer ts.Close()
+
+	b := newBrowser(t, ts.URL)
+	get := func(path, accept string) (*http.Response, string) {
+		t.Helper()
+
+		req, _ := http.NewRequest("GET", ts.URL+path, nil)
+		req.Header.Set("Accept-Language", accept)
+
+		res, err := b.client.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %s", err)
+		}
+		defer res.Body.Close()
+
+		body, _ := io.ReadAll(res.Body)
+		return res, string(body)
+	}
+
+	if res, page := get("/", "es-ES,es;q=0.9"); !strings.Contains(page, `lang="es"`) || !strings.Contains(page, "Nueva partida") || res.Header.Get("Content-Language") != "es" {
+		t.Fatal("expected the page in the browser's language")
+	}
+
+	if _, page := get("/", "ja"); !strings.Contains(page, `lang="en"`) || !strings.Contains(page, "New game") {
+		t.Fatal("expected English when no catalog matches")
+	}
+
+	// Choosing a language sticks, whatever the browser prefers
+	if _, page := get("/?lang=de", "es"); !strings.Contains(page, "Neues Spiel") {
+		t.Fatal("expected the chosen language")
+	}
+	if _, page := get("/leaderboard", "es"); !strings.Contains(page, "Rangliste") {
+		t.Fatal("expected the chosen language to be remembered")
+	}
+
+	if _, page := get("/games/missing", "es"); !strings.Contains(page, "Nicht gefunden") || !strings.Contains(page, "Spiel nicht gefunden") || !strings.Contains(page, "Zurück zur Startseite") {
+		t.Fatal("expected error pages in the chosen language")
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"time"
+
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Header requests are identified by, both ways. An ID sent by a proxy in
+// front of the server is kept so logs can be matched up across the two.
+const REQUEST_ID_HEADER = "X-Request-ID"
+
+// IDs accepted from proxies, so nobody can fill the logs with junk
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// Routes orchestrators poll every few seconds, only logged when
+// debugging or when they fail
+var probeRoutes = map[string]bool{"/healthz": true, "/readyz": true}
+
+type requestIDKey struct{}
+
+// Gives every request an ID, sent back in REQUEST_ID_HEADER and included
+// in everything logged while handling it.
+func (s *Server) identifyRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(REQUEST_ID_HEADER)
+		if !validRequestID.MatchString(id) {
+			var err error
+			if id, err = game.NewID(); err != nil {
+				http.Error(w, "internal error", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		w.Header().Set(REQUEST_ID_HEADER, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// Returns the ID given to r by identifyRequest, empty if there isn't one.
+func requestID(r *http.Request) string {
+	id, _ := r.Context().Value(requestIDKey{}).(string)
+	return id
+}
+
+// Returns the logger for anything to do with handling r.
+func (s *Server) logger(r *http.Request) *slog.Logger {
+	if id := requestID(r); id != "" {
+		return s.log.With("request", id)
+	}
+	return s.log
+}
+
+// Logs every request once it has been handled. Only the route pattern is
+// logged, not the path, as some paths carry seat tokens.
+func (s *Server) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		route := chi.RouteContext(r.Context()).RoutePattern()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case probeRoutes[route]:
+			level = slog.LevelDebug
+		}
+
+		s.logger(r).Log(r.Context(), level, "Handled request",
+			"method", r.Method,
+			"route", route,
+			"status", status,
+			"duration", time.Since(start),
+			"bytes", ww.BytesWritten(),
+		)
+	})
+}
+
+// Turns a panicking handler into a logged error and, if nothing has been
+// written yet, an error page, rather than a dropped connection.
+func (s *Server) recoverPanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+
+			// Used on purpose to abort a response, see http.ErrAbortHandler
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			s.logger(r).Error("Panic handling request", "panic", rec, "stack", string(debug.Stack()))
+
+			if ww.Status() == 0 {
+				renderError(w, r, http.StatusInternalServerError, locale(r).T(SERVER_ERROR))
+			}
+		}()
+
+		next.ServeHTTP(ww, r)
+	})
+}
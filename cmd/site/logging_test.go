@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alfiehiscox/submarines/pkg/api"
+)
+
+// Collects logs written from handlers.
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestRequestLogging(t *testing.T) {
+	logs := &logBuffer{}
+	log := slog.New(slog.NewTextHandler(logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	s := NewServer(log, Options{})
+	s.setUpRoutes()
+	ts := httptest.NewServer(s.mux)
+	defer ts.Close()
+
+	// Kept when sent by a proxy
+	req, _ := http.NewRequest("POST", ts.URL+"/api/v1/games", strings.NewReader(`{"name":"alpha"}`))
+	req.Header.Set(REQUEST_ID_HEADER, "proxy-123")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %s", err)
+	}
+	res.Body.Close()
+
+	if id := res.Header.Get(REQUEST_ID_HEADER); id != "proxy-123" {
+		t.Fatalf("expected the proxy's request id, got %q", id)
+	}
+
+	for _, want := range []string{
+		`msg="Handled request" request=proxy-123 method=POST route=/api/v1/games status=201`,
+		`msg="Game event" game=`,
+		`type=JOINED seat=0 request=proxy-123`,
+	} {
+		if !strings.Contains(logs.String(), want) {
+			t.Fatalf("expected logs to contain %s, got:\n%s", want, logs)
+		}
+	}
+
+	// Replaced when it isn't fit to log
+	req, _ = http.NewRequest("GET", ts.URL+"/", nil)
+	req.Header.Set(REQUEST_ID_HEADER, "two words")
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %s", err)
+	}
+	res.Body.Close()
+
+	if id := res.Header.Get(REQUEST_ID_HEADER); id == "" || id == "two words" {
+		t.Fatalf("expected a new request id, got %q", id)
+	}
+}
+
+func TestRecoverPanics(t *testing.T) {
+	logs := &logBuffer{}
+	s := NewServer(slog.New(slog.NewTextHandler(logs, nil)), Options{})
+	s.setUpRoutes()
+	s.mux.Get("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("oops")
+	})
+	ts := httptest.NewServer(s.mux)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/panic")
+	if err != nil {
+		t.Fatalf("request failed: %s", err)
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
+
+	if res.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", res.StatusCode)
+	}
+
+	id := res.Header.Get(REQUEST_ID_HEADER)
+	if !strings.Contains(string(body), "Request ID: "+id) {
+		t.Fatalf("expected an error page showing the request id, got %s", body)
+	}
+
+	for _, want := range []string{
+		`level=ERROR msg="Panic handling request" request=` + id + ` panic=oops`,
+		`level=ERROR msg="Handled request" request=` + id + ` method=GET route=/panic status=500`,
+	} {
+		if !strings.Contains(logs.String(), want) {
+			t.Fatalf("expected logs to contain %s, got:\n%s", want, logs)
+		}
+	}
+
+	// Still serving
+	if status := doJSON(t, "POST", ts.URL+"/api/v1/games", "", api.JoinRequest{Name: "alpha"}, nil); status != http.StatusCreated {
+		t.Fatalf("expected 201 after a panic, got %d", status)
+	}
+}
@@ -3,37 +3,113 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
+	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
-	"github.com/alfiehiscox/submarines/pkg/board"
+	"github.com/alfiehiscox/submarines/pkg/cell"
+	"github.com/alfiehiscox/submarines/pkg/chat"
+	"github.com/alfiehiscox/submarines/pkg/game"
 	"github.com/alfiehiscox/submarines/pkg/html"
+	"github.com/alfiehiscox/submarines/pkg/storage"
+	"github.com/alfiehiscox/submarines/pkg/view"
+	"github.com/alfiehiscox/submarines/static"
 	"github.com/go-chi/chi/v5"
 	"golang.org/x/sync/errgroup"
 	. "maragu.dev/gomponents"
-	ghttp "maragu.dev/gomponents/http"
 )
 
 func main() {
-	log := slog.New(slog.NewTextHandler(os.Stderr, nil))
-	if err := start(log); err != nil {
+	cfg, err := LoadConfig(os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error reading config:", err)
+		os.Exit(2)
+	}
+
+	log := cfg.Logger()
+
+	opts := Options{
+		Addr:              cfg.Addr,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		TLSCert:           cfg.TLSCert,
+		TLSKey:            cfg.TLSKey,
+		ForfeitTimeout:    cfg.ForfeitTimeout,
+		BotMoveTimeout:    cfg.BotMoveTimeout,
+		TimeControl:       cfg.TimeControl(),
+		RateLimit:         cfg.RateLimit,
+	}
+
+	// Served from disk while working on the stylesheet
+	if cfg.StaticDir != "" {
+		assets, err := static.New(os.DirFS(cfg.StaticDir))
+		if err != nil {
+			log.Error("Error reading static files:", "error", err)
+			os.Exit(1)
+		}
+		opts.Assets = assets
+	}
+
+	if cfg.DB == "" {
+		opts.Repository = storage.NewMemory()
+	} else {
+		repo, err := storage.OpenBolt(cfg.DB)
+		if err != nil {
+			log.Error("Error opening database:", "error", err)
+			os.Exit(1)
+		}
+		opts.Repository = repo
+	}
+	defer opts.Repository.Close()
+
+	if cfg.ChatFilter != "" {
+		filter, err := chat.LoadFilter(cfg.ChatFilter)
+		if err != nil {
+			log.Error("Error reading chat filter:", "error", err)
+			os.Exit(1)
+		}
+		opts.ChatFilter = filter
+	}
+
+	// Keys are secrets, so they only come from the environment
+	botKeys, err := ParseBotKeys(os.Getenv("SUBMARINES_BOT_KEYS"))
+	if err != nil {
+		log.Error("Error reading SUBMARINES_BOT_KEYS:", "error", err)
+		os.Exit(1)
+	}
+	opts.BotKeys = botKeys
+
+	if err := start(log, opts); err != nil {
 		log.Error("Error starting app:", "error", err)
+		opts.Repository.Close()
 		os.Exit(1)
 	}
 }
 
-func start(log *slog.Logger) error {
+func start(log *slog.Logger, opts Options) error {
 	log.Info("Starting app")
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
 	defer stop()
 
-	server := NewServer(log)
+	server := NewServer(log, opts)
+	if err := server.Restore(); err != nil {
+		return err
+	}
 
 	eg, ctx := errgroup.WithContext(ctx)
 
@@ -57,52 +133,286 @@ func start(log *slog.Logger) error {
 	return nil
 }
 
+type Options struct {
+	// Where to listen, ":8080" if empty
+	Addr string
+
+	// Zero means no limit
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+
+	// Serves HTTPS when set
+	TLSCert string
+	TLSKey  string
+
+	// Static files to serve, nil for the ones built in
+	Assets *static.Assets
+
+	ForfeitTimeout time.Duration
+	BotMoveTimeout time.Duration
+
+	// Limits on how long players take to fire in new games
+	TimeControl game.TimeControl
+
+	// Masks unwanted words in chat, nil to allow anything
+	ChatFilter *chat.Filter
+
+	// Limits how quickly each client can make requests and bans those
+	// making many bad moves, see Guard
+	RateLimit bool
+
+	// Bot names by the key they authenticate with
+	BotKeys map[string]string
+
+	// Where games and accounts are saved, nil to keep them in memory
+	Repository storage.Repository
+}
+
 type Server struct {
-	log    *slog.Logger
-	mux    chi.Router
-	server *http.Server
+	log        *slog.Logger
+	mux        chi.Router
+	server     *http.Server
+	games      *game.Registry
+	matchmaker *game.Matchmaker
+	repo       storage.Repository
+	metrics    *metrics
+	guard      *Guard // nil without rate limits
+
+	// Games still being saved, see storage.Persist
+	persisting sync.WaitGroup
+
+	// Held while updating ratings, see rate
+	rating sync.Mutex
+
+	// Parent of every request context, cancelled to end event streams
+	// and sockets on shutdown
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// Set as soon as Stop begins, so the server reports itself unready
+	// and no new traffic is sent its way
+	stopping atomic.Bool
+
+	assets         *static.Assets
+	tlsCert        string
+	tlsKey         string
+	botKeys        map[string]string
+	botMoveTimeout time.Duration
 }
 
-func NewServer(log *slog.Logger) *Server {
+func NewServer(log *slog.Logger, opts Options) *Server {
 	mux := chi.NewMux()
-	return &Server{
-		log: log,
-		mux: mux,
+	ctx, cancel := context.WithCancel(context.Background())
+
+	repo := opts.Repository
+	if repo == nil {
+		repo = storage.NewMemory()
+	}
+
+	addr := opts.Addr
+	if addr == "" {
+		addr = ":8080"
+	}
+
+	assets := opts.Assets
+	if assets == nil {
+		assets = static.Embedded()
+	}
+	html.Assets = assets
+
+	games := game.NewRegistry()
+	games.ForfeitTimeout = opts.ForfeitTimeout
+	games.TimeControl = opts.TimeControl
+	games.ChatFilter = opts.ChatFilter
+	matchmaker := game.NewMatchmaker(games)
+
+	// Counts moves as they happen, and logs events along with the
+	// requests that caused them
+	metrics := newMetrics(games, matchmaker)
+	games.OnEvent = func(event game.Event) {
+		metrics.observe(event)
+		log.Debug("Game event", "game", event.Game, "type", event.Type, "seat", event.Seat, "request", event.Request)
+	}
+
+	s := &Server{
+		log:            log,
+		mux:            mux,
+		games:          games,
+		matchmaker:     matchmaker,
+		repo:           repo,
+		metrics:        metrics,
+		assets:         assets,
+		tlsCert:        opts.TLSCert,
+		tlsKey:         opts.TLSKey,
+		ctx:            ctx,
+		cancel:         cancel,
+		botKeys:        opts.BotKeys,
+		botMoveTimeout: opts.BotMoveTimeout,
 		server: &http.Server{
-			Addr:              ":8080",
+			Addr:              addr,
 			Handler:           mux,
-			ReadTimeout:       5 * time.Second,
-			WriteTimeout:      5 * time.Second,
-			ReadHeaderTimeout: 5 * time.Second,
-			IdleTimeout:       5 * time.Second,
+			ReadTimeout:       opts.ReadTimeout,
+			WriteTimeout:      opts.WriteTimeout,
+			ReadHeaderTimeout: opts.ReadHeaderTimeout,
+			IdleTimeout:       opts.IdleTimeout,
+			BaseContext:       func(net.Listener) context.Context { return ctx },
 		},
 	}
+
+	if opts.RateLimit {
+		s.guard = NewGuard()
+	}
+
+	games.OnAdd = func(g *game.Game) {
+		s.persisting.Add(1)
+		go func() {
+			defer s.persisting.Done()
+			storage.Persist(s.repo, g, log)
+
+			if err := s.rate(g); err != nil {
+				log.Error("Error rating game", "game", g.ID, "error", err)
+			}
+
+			// Saved for the last time, so it is read from the repository
+			// from now on
+			if g.Snapshot().Phase == game.FINISHED {
+				s.games.Remove(g.ID)
+			}
+		}()
+	}
+
+	return s
 }
 
 func (s *Server) setUpRoutes() {
+	s.mux.Use(s.identifyRequest, secureHeaders(contentSecurityPolicy()), localize, s.logRequests, s.metrics.instrument, s.recoverPanics)
+
 	// Static
-	fs := http.FileServer(http.Dir("static"))
-	s.mux.Handle("/static/*", http.StripPrefix("/static/", fs))
+	s.mux.Handle(static.PREFIX+"*", http.StripPrefix(static.PREFIX, s.assets))
+
+	// Pages sit behind sessions and CSRF checks. The API and bots
+	// authenticate with bearer tokens instead, which browsers never send
+	// on their own.
+	s.mux.Group(func(r chi.Router) {
+		r.Use(s.authenticate, verifyCSRF, s.limit(PAGE_BUDGET))
 
-	// Page Routes
-	s.mux.Get("/", ghttp.Adapt(IndexHandler))
-	s.mux.Get("/place-ships", ghttp.Adapt(PlaceShipsHandler))
-	s.mux.Get("/ship-select/{id}", ghttp.Adapt(ShipSelectHandler))
+		// Page Routes
+		r.Get("/", s.adapt(IndexHandler))
+		r.Get("/place-ships", s.adapt(PlaceShipsHandler))
+		r.Get("/ship-select/{id}", s.adapt(ShipSelectHandler))
+		r.Post("/place-ships/{x}/{y}", s.adapt(PlaceShipHandler))
+
+		// Account Routes
+		r.Get("/register", s.adapt(RegisterHandler))
+		r.Post("/register", s.adapt(s.RegisterFormHandler))
+		r.Get("/login", s.adapt(LoginHandler))
+		r.Post("/login", s.adapt(s.LoginFormHandler))
+		r.Post("/logout", s.adapt(s.LogoutHandler))
+
+		// Game Routes
+		r.Get("/play", s.adapt(PlayHandler))
+		r.Get("/play/events", s.QueueEventsHandler)
+		r.Post("/games", s.adapt(s.NewGameHandler))
+		r.Get("/games/{id}", s.adapt(s.GameHandler))
+		r.Post("/games/{id}/join", s.adapt(s.JoinGameHandler))
+		r.Get("/games/{id}/resume/{token}", s.adapt(s.ResumeHandler))
+		r.Post("/games/{id}/shuffle", s.adapt(s.ShuffleHandler))
+		r.Post("/games/{id}/ready", s.adapt(s.ReadyHandler))
+		r.With(s.limit(SHOT_BUDGET)).Post("/games/{id}/fire/{x}/{y}", s.adapt(s.FireHandler))
+		r.Get("/games/{id}/events", s.GameEventsHandler)
+
+		// Chat Routes
+		r.Group(func(r chi.Router) {
+			r.Use(s.limit(CHAT_BUDGET))
+			r.Post("/games/{id}/chat", s.adapt(s.ChatHandler))
+			r.Post("/games/{id}/chat/mute", s.adapt(s.MuteHandler))
+			r.Post("/games/{id}/chat/report", s.adapt(s.ReportHandler))
+		})
+
+		// Replay Routes
+		r.Get("/history", s.adapt(s.HistoryHandler))
+		r.Get("/games/{id}/replay", s.adapt(s.ReplayHandler))
+		r.Get("/games/{id}/replay/{move}", s.adapt(s.ReplayMoveHandler))
+
+		// Rating Routes
+		r.Get("/leaderboard", s.adapt(s.LeaderboardHandler))
+		r.Get("/players/{name}", s.adapt(s.PlayerHandler))
+
+		// Spectator Routes
+		r.Get("/watch", s.adapt(s.WatchHandler))
+		r.Get("/games/{id}/watch", s.adapt(s.SpectateHandler))
+		r.Get("/games/{id}/watch/events", s.SpectateEventsHandler)
+	})
+
+	// Operations
+	s.mux.Handle("/metrics", s.metrics.Handler())
+	s.mux.Get("/healthz", HealthzHandler)
+	s.mux.Get("/readyz", s.ReadyzHandler)
+	s.mux.Get("/version", VersionHandler)
+
+	// API Routes
+	s.mux.Get("/api/openapi.json", OpenAPIHandler)
+	s.mux.Route("/api/v1", s.setUpAPIRoutes)
+}
+
+// Loads the games in progress saved in the repository, so they can carry
+// on where they left off. Finished games stay in the repository, where
+// history and replays read them.
+func (s *Server) Restore() error {
+	records, err := s.repo.Games()
+	if err != nil {
+		return err
+	}
+
+	restored := 0
+	for _, record := range records {
+		if record.Phase == game.FINISHED {
+			continue
+		}
+		s.games.Add(game.Restore(record))
+		restored++
+	}
+
+	s.log.Info("Restored games", "count", restored)
+	return nil
 }
 
 func (s *Server) Start() error {
-	s.log.Info("Starting HTTP Server", "address", "localhost:8080")
+	s.log.Info("Starting HTTP Server", "address", s.server.Addr, "tls", s.tlsCert != "")
 	s.setUpRoutes()
-	if err := s.server.ListenAndServe(); err != nil {
+
+	var err error
+	if s.tlsCert != "" {
+		err = s.server.ListenAndServeTLS(s.tlsCert, s.tlsKey)
+	} else {
+		err = s.server.ListenAndServe()
+	}
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		return err
 	}
 	return nil
 }
 
+// Suspends every game, which tells connected players and refuses any
+// more moves, waits for them to be saved so the next server can restore
+// them, then shuts down the HTTP server.
 func (s *Server) Stop() error {
+	s.stopping.Store(true)
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
+	s.games.Suspend()
+	if err := s.checkpoint(ctx); err != nil {
+		return err
+	}
+
+	// Event streams and sockets never end by themselves
+	s.cancel()
+
 	if err := s.server.Shutdown(ctx); err != nil {
 		return err
 	}
@@ -111,13 +421,106 @@ func (s *Server) Stop() error {
 	return nil
 }
 
+// Waits for every game to be saved for the last time.
+func (s *Server) checkpoint(ctx context.Context) error {
+	saved := make(chan struct{})
+	go func() {
+		s.persisting.Wait()
+		close(saved)
+	}()
+
+	select {
+	case <-saved:
+		s.log.Info("Saved games")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("saving games: %w", ctx.Err())
+	}
+}
+
 // Handlers
 func PlaceShipsHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
-	return html.PlaceShips(board.NewBoard()), nil
+	p, _ := view.ForPlacement(nil, 1, cell.HORIZONTAL, 0)
+	return html.PlaceShips(locale(r), p), nil
+}
+
+// Places the chosen ship with its top left end at {x}/{y}. Ships placed so
+// far come back with the form, so nothing is kept on the server.
+func PlaceShipHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
+	x, err := strconv.Atoi(chi.URLParam(r, "x"))
+	if err != nil {
+		return nil, badRequest("error.coordinates", err)
+	}
+	y, err := strconv.Atoi(chi.URLParam(r, "y"))
+	if err != nil {
+		return nil, badRequest("error.coordinates", err)
+	}
+	coord, err := cell.NewCoordinate(x, y)
+	if err != nil {
+		return nil, badRequest("error.coordinate", err)
+	}
+
+	if err := r.ParseForm(); err != nil {
+		return nil, badRequest("error.form", err)
+	}
+
+	chosen, err := strconv.Atoi(r.PostForm.Get("ship"))
+	if err != nil || chosen < 1 || chosen > len(game.FLEET) {
+		return nil, badRequest("error.choose_ship", err)
+	}
+
+	orientation := cell.Orientation(r.PostForm.Get("orientation"))
+	if orientation != cell.HORIZONTAL && orientation != cell.VERTICAL {
+		return nil, badRequest("error.orientation", nil)
+	}
+
+	ships := make(map[int]game.Ship)
+	for id := 1; id <= len(game.FLEET); id++ {
+		value := r.PostForm.Get(fmt.Sprintf("ship%d", id))
+		if value == "" || id == chosen {
+			continue
+		}
+
+		var x, y int
+		var o string
+		if _, err := fmt.Sscanf(value, "%d,%d,%s", &x, &y, &o); err != nil {
+			return nil, badRequest("error.placement", err)
+		}
+		coord, err := cell.NewCoordinate(x, y)
+		if err != nil {
+			return nil, badRequest("error.placement", err)
+		}
+		ship := game.Ship{Size: game.FLEET[id-1], Orientation: cell.Orientation(o), Coordinate: coord}
+		if ship.Orientation != cell.HORIZONTAL && ship.Orientation != cell.VERTICAL {
+			return nil, badRequest("error.placement", nil)
+		}
+		ships[id] = ship
+	}
+	ships[chosen] = game.Ship{Size: game.FLEET[chosen-1], Orientation: orientation, Coordinate: coord}
+
+	// Moves on to the next ship still to be placed, if there is one
+	next := 0
+	for i := 1; i < len(game.FLEET); i++ {
+		id := (chosen+i-1)%len(game.FLEET) + 1
+		if _, ok := ships[id]; !ok {
+			next = id
+			break
+		}
+	}
+
+	p, err := view.ForPlacement(ships, next, orientation, chosen)
+	if err != nil {
+		return nil, badRequest("error.ship_fit", err)
+	}
+
+	return render(w, r, html.Placement(locale(r), p), html.PlaceShips(locale(r), p)), nil
 }
 
 func IndexHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
-	return html.Index(), nil
+	if a, ok := accountFrom(r); ok {
+		return html.Index(locale(r), &a), nil
+	}
+	return html.Index(locale(r), nil), nil
 }
 
 func ShipSelectHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
@@ -125,12 +528,13 @@ func ShipSelectHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
 	id_string := chi.URLParam(r, "id")
 	id, err := strconv.Atoi(id_string)
 	if err != nil {
-		return nil, err
+		return nil, badRequest("error.ship_id", err)
 	}
 
-	if id < 1 || id > 5 {
-		return nil, errors.New("ship id unavailable")
+	if id < 1 || id > len(game.FLEET) {
+		return nil, notFound("error.ship_unavailable")
 	}
 
-	return html.ShipGallery(id), nil
+	p, _ := view.ForPlacement(nil, id, cell.HORIZONTAL, 0)
+	return render(w, r, html.ShipGallery(locale(r), id), html.PlaceShips(locale(r), p)), nil
 }
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alfiehiscox/submarines/pkg/api"
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/alfiehiscox/submarines/pkg/storage"
+)
+
+func TestStopCheckpointsGames(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repo := storage.NewMemory()
+
+	s := NewServer(log, Options{Repository: repo})
+	s.setUpRoutes()
+	ts := httptest.NewServer(s.mux)
+	defer ts.Close()
+
+	base := ts.URL + "/api/v1/games"
+	var first, second api.JoinResponse
+	doJSON(t, "POST", base, "", api.JoinRequest{Name: "first"}, &first)
+	games := base + "/" + first.Game.ID
+	doJSON(t, "POST", games+"/join", "", api.JoinRequest{Name: "second"}, &second)
+
+	for _, token := range []string{first.Token, second.Token} {
+		doJSON(t, "PUT", games+"/fleet", token, api.FleetRequest{Random: true}, nil)
+		doJSON(t, "POST", games+"/ready", token, nil, nil)
+	}
+
+	if err := s.Stop(); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	shot := api.ShotRequest{Coordinate: api.Coordinate{X: 0, Y: 0}}
+	if status := doJSON(t, "POST", games+"/shots", first.Token, shot, nil); status != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 once stopped, got %d", status)
+	}
+
+	record, err := repo.Game(first.Game.ID)
+	if err != nil || record.Phase != game.PLAYING {
+		t.Fatalf("expected the game in progress to be saved, got %v %v", record.Phase, err)
+	}
+
+	// The next server carries on where the last left off
+	next := NewServer(log, Options{Repository: repo})
+	if err := next.Restore(); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	next.setUpRoutes()
+	nextTS := httptest.NewServer(next.mux)
+	defer nextTS.Close()
+
+	games = nextTS.URL + "/api/v1/games/" + first.Game.ID
+	if status := doJSON(t, "POST", games+"/shots", first.Token, shot, nil); status != http.StatusOK {
+		t.Fatalf("expected 200 after restoring, got %d", status)
+	}
+}
+
+func TestFinishedGamesLeaveMemory(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	repo := storage.NewMemory()
+
+	s := NewServer(log, Options{Repository: repo})
+	s.setUpRoutes()
+	ts := httptest.NewServer(s.mux)
+	defer ts.Close()
+
+	start := func() *game.Game {
+		g, _ := s.games.Create()
+		g.Join("first")
+		g.Join("second")
+		g.RandomizeFleet(0)
+		g.RandomizeFleet(1)
+		g.Ready(0)
+		g.Ready(1)
+		return g
+	}
+
+	finished, playing := start(), start()
+	finished.Resign(1)
+
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(5 * time.Millisecond) {
+		if _, ok := s.games.Get(finished.ID); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the finished game to be forgotten once saved")
+		}
+	}
+
+	// Its pages are still served, from the repository
+	res, err := http.Get(ts.URL + "/games/" + finished.ID + "/watch")
+	if err != nil {
+		t.Fatalf("request failed: %s", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || !strings.Contains(string(body), "first won!") {
+		t.Fatalf("expected the finished game to be shown, got %d", res.StatusCode)
+	}
+
+	next := NewServer(log, Options{Repository: repo})
+	if err := next.Restore(); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if _, ok := next.games.Get(finished.ID); ok {
+		t.Fatal("expected finished games to be left in the repository")
+	}
+	if _, ok := next.games.Get(playing.ID); !ok {
+		t.Fatal("expected games in progress to be restored")
+	}
+}
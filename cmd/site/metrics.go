@@ -0,0 +1,144 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// What the server exports on /metrics for Prometheus to scrape.
+type metrics struct {
+	registry *prometheus.Registry
+
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	sessions *prometheus.GaugeVec
+	moves    prometheus.Counter
+	botThink prometheus.Histogram
+}
+
+func newMetrics(games *game.Registry, matchmaker *game.Matchmaker) *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "submarines_http_requests_total",
+			Help: "HTTP requests handled, by route pattern, method and status code.",
+		}, []string{"route", "method", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "submarines_http_request_duration_seconds",
+			Help:    "Time taken to handle HTTP requests, by route pattern and method. Event streams last as long as they are open.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+		sessions: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "submarines_active_sessions",
+			Help: "Open connections, by kind: player and spectator event streams, players waiting for a match, and bot sockets.",
+		}, []string{"kind"}),
+		moves: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "submarines_moves_total",
+			Help: "Shots fired in every game.",
+		}),
+		botThink: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "submarines_bot_think_seconds",
+			Help:    "Time bots take to fire after being told it is their turn.",
+			Buckets: prometheus.ExponentialBuckets(0.01, 2, 12),
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.latency,
+		m.sessions,
+		m.moves,
+		m.botThink,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "submarines_matchmaking_queue_length",
+			Help: "Players waiting to be matched.",
+		}, func() float64 {
+			return float64(matchmaker.Len())
+		}),
+		gamesCollector{games: games},
+	)
+
+	return m
+}
+
+func (m *metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Counts and times requests by the route pattern they matched, so
+// games don't each get their own series.
+func (m *metrics) instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		route := chi.RouteContext(r.Context()).RoutePattern()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		m.requests.WithLabelValues(route, r.Method, strconv.Itoa(status)).Inc()
+		m.latency.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+	})
+}
+
+// Counts a connection of the given kind as open, returning a function
+// to call when it closes.
+func (m *metrics) session(kind string) func() {
+	gauge := m.sessions.WithLabelValues(kind)
+	gauge.Inc()
+	return gauge.Dec
+}
+
+// Watches every game's events. Runs while the game is locked.
+func (m *metrics) observe(event game.Event) {
+	if event.Type == game.FIRED {
+		m.moves.Inc()
+	}
+}
+
+var gamesDesc = prometheus.NewDesc(
+	"submarines_games_in_progress",
+	"Games that have not finished, by phase.",
+	[]string{"phase"}, nil,
+)
+
+// Counts games in progress whenever metrics are scraped.
+type gamesCollector struct {
+	games *game.Registry
+}
+
+func (c gamesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- gamesDesc
+}
+
+func (c gamesCollector) Collect(ch chan<- prometheus.Metric) {
+	phases := map[game.Phase]int{game.PLACING: 0, game.PLAYING: 0}
+	for _, g := range c.games.Live() {
+		// May have finished since
+		if phase := g.Snapshot().Phase; phase != game.FINISHED {
+			phases[phase]++
+		}
+	}
+
+	for phase, count := range phases {
+		ch <- prometheus.MustNewConstMetric(gamesDesc, prometheus.GaugeValue, float64(count), string(phase))
+	}
+}
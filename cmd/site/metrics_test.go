@@ -0,0 +1,41 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alfiehiscox/submarines/pkg/cell"
+)
+
+func TestMetrics(t *testing.T) {
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{})
+	s.setUpRoutes()
+	ts := httptest.NewServer(s.mux)
+	defer ts.Close()
+
+	g, _ := s.games.Create()
+	for _, name := range []string{"alpha", "beta"} {
+		seat, _, _ := g.Join(name)
+		g.RandomizeFleet(seat)
+		g.Ready(seat)
+	}
+	g.Fire(0, cell.Coordinate{0, 0})
+
+	b := newBrowser(t, ts.URL)
+	b.get("/games/" + g.ID + "/watch")
+
+	page := b.get("/metrics")
+	for _, want := range []string{
+		`submarines_http_requests_total{method="GET",route="/games/{id}/watch",status="200"} 1`,
+		`submarines_games_in_progress{phase="PLAYING"} 1`,
+		`submarines_moves_total 1`,
+		`submarines_matchmaking_queue_length 0`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("expected metrics to contain %s", want)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/alfiehiscox/submarines/pkg/api"
+	"github.com/alfiehiscox/submarines/pkg/client"
+	"github.com/go-chi/chi/v5"
+)
+
+type openAPIDocument struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+func loadOpenAPI(t *testing.T) openAPIDocument {
+	t.Helper()
+
+	var doc openAPIDocument
+	if err := json.Unmarshal(openAPISpec, &doc); err != nil {
+		t.Fatalf("openapi.json is not valid: %s", err)
+	}
+	return doc
+}
+
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{})
+	s.setUpRoutes()
+
+	routes := map[string]bool{}
+	err := chi.Walk(s.mux, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if path, ok := strings.CutPrefix(route, "/api/v1"); ok {
+			routes[strings.ToLower(method)+" "+path] = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed in set up: %s", err)
+	}
+
+	documented := map[string]bool{}
+	for path, item := range loadOpenAPI(t).Paths {
+		for method := range item {
+			if method != "parameters" {
+				documented[method+" "+path] = true
+			}
+		}
+	}
+
+	for route := range routes {
+		if !documented[route] {
+			t.Errorf("route %q is missing from openapi.json", route)
+		}
+	}
+
+	for route := range documented {
+		if !routes[route] {
+			t.Errorf("openapi.json documents %q which has no handler", route)
+		}
+	}
+}
+
+func TestOpenAPIMatchesTypes(t *testing.T) {
+	types := map[string]any{
+		"ErrorResponse": api.ErrorResponse{},
+		"Error":         api.Error{},
+		"Coordinate":    api.Coordinate{},
+		"JoinRequest":   api.JoinRequest{},
+		"JoinResponse":  api.JoinResponse{},
+		"Ship":          api.Ship{},
+		"FleetRequest":  api.FleetRequest{},
+		"ShotRequest":   api.ShotRequest{},
+		"Move":          api.Move{},
+		"MovesResponse": api.MovesResponse{},
+		"Player":        api.Player{},
+		"Game":          api.Game{},
+		"Message":       api.Message{},
+	}
+
+	schemas := loadOpenAPI(t).Components.Schemas
+	if len(schemas) != len(types) {
+		t.Errorf("expected %d schemas, got %d", len(types), len(schemas))
+	}
+
+	for name, v := range types {
+		schema, ok := schemas[name]
+		if !ok {
+			t.Errorf("schema %s is missing from openapi.json", name)
+			continue
+		}
+
+		var documented []string
+		for property := range schema.Properties {
+			documented = append(documented, property)
+		}
+		sort.Strings(documented)
+
+		fields := jsonFields(reflect.TypeOf(v))
+		sort.Strings(fields)
+
+		if !reflect.DeepEqual(documented, fields) {
+			t.Errorf("schema %s has properties %v, type has %v", name, documented, fields)
+		}
+	}
+}
+
+// Returns the JSON names of typ's fields, flattening embedded structs.
+func jsonFields(typ reflect.Type) []string {
+	var fields []string
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Anonymous {
+			fields = append(fields, jsonFields(field.Type)...)
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+func TestClientPlaysGame(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := context.Background()
+
+	first, second := client.New(ts.URL), client.New(ts.URL)
+
+	created, err := first.CreateGame(ctx, "first")
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	id := created.Game.ID
+
+	if _, err := second.JoinGame(ctx, id, "second"); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	for _, c := range []*client.Client{first, second} {
+		if _, err := c.RandomFleet(ctx, id); err != nil {
+			t.Fatalf("err should be nil: %s", err)
+		}
+		if _, err := c.Ready(ctx, id); err != nil {
+			t.Fatalf("err should be nil: %s", err)
+		}
+	}
+
+	var apiErr api.Error
+	if _, err := second.Fire(ctx, id, 0, 0); !errors.As(err, &apiErr) || apiErr.Code != api.NOT_YOUR_TURN {
+		t.Fatalf("expected not_your_turn, got %v", err)
+	}
+
+	// Both seats sweep the board in order until someone wins
+	players := []*client.Client{first, second}
+	state, err := first.Game(ctx, id)
+	for i := 0; err == nil && state.Phase != "FINISHED"; i++ {
+		state, err = players[i%2].Fire(ctx, id, (i/2)%10, (i/2)/10)
+	}
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	moves, err := first.Moves(ctx, id, 0)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	if len(moves) != len(state.Moves) || state.Winner < 0 {
+		t.Fatalf("expected a finished game, got %d moves winner %d", len(moves), state.Winner)
+	}
+}
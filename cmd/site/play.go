@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/alfiehiscox/submarines/pkg/html"
+	. "maragu.dev/gomponents"
+)
+
+func PlayHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
+	return html.Queue(locale(r)), nil
+}
+
+// Holds a place in the matchmaking queue for as long as the browser stays
+// connected, then sends it to the game it was matched into.
+func (s *Server) QueueEventsHandler(w http.ResponseWriter, r *http.Request) {
+	a, err := s.identify(w, r)
+	if err != nil {
+		s.logger(r).Error("Error identifying player", "error", err)
+		http.Error(w, "could not join the queue", http.StatusInternalServerError)
+		return
+	}
+
+	ticket, err := s.matchmaker.EnqueueAs(a.Name, a.ID)
+	if err != nil {
+		s.logger(r).Error("Error joining matchmaking queue", "error", err)
+		http.Error(w, "could not join the queue", http.StatusInternalServerError)
+		return
+	}
+
+	defer s.metrics.session("queue")()
+
+	stream, err := newEventStream(w)
+	if err != nil {
+		s.matchmaker.Cancel(ticket)
+		s.logger(r).Error("Error opening event stream", "error", err)
+		return
+	}
+
+	select {
+	case <-r.Context().Done():
+		s.matchmaker.Cancel(ticket)
+
+		// Matched just as we left, so nobody is going to play the seat
+		select {
+		case match := <-ticket.Matched():
+			_ = match.Game.Resign(match.Seat)
+		default:
+		}
+
+	case match := <-ticket.Matched():
+		resume := fmt.Sprintf("/games/%s/resume/%s", match.Game.ID, match.Token)
+		_ = stream.Send("matched", html.Matched(locale(r), resume))
+	}
+}
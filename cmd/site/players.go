@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/alfiehiscox/submarines/pkg/html"
+	"github.com/alfiehiscox/submarines/pkg/stats"
+	"github.com/alfiehiscox/submarines/pkg/storage"
+	"github.com/go-chi/chi/v5"
+	. "maragu.dev/gomponents"
+)
+
+var errPlayerNotFound = notFound("error.player_not_found")
+
+func (s *Server) PlayerHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
+	a, err := s.repo.AccountByName(chi.URLParam(r, "name"))
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, errPlayerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	games, err := s.repo.AccountGames(a.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.repo.RatingHistory(a.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return html.Player(locale(r), a, stats.Compute(a.ID, games), history), nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/alfiehiscox/submarines/pkg/html"
+	"github.com/alfiehiscox/submarines/pkg/rating"
+	"github.com/alfiehiscox/submarines/pkg/storage"
+	. "maragu.dev/gomponents"
+)
+
+// Accounts shown on the leaderboard
+const LEADERBOARD_SIZE = 100
+
+// Updates the ratings of both accounts once a rated game has finished.
+// Safe to call more than once for the same game.
+func (s *Server) rate(g *game.Game) error {
+	record := g.Record()
+	if !record.Rated || record.Phase != game.FINISHED || record.Winner == game.NO_WINNER {
+		return nil
+	}
+
+	first, second := record.Seats[0].Account, record.Seats[1].Account
+	if first == "" || second == "" || first == second {
+		return nil
+	}
+
+	// Ratings are read, updated and written back as one
+	s.rating.Lock()
+	defer s.rating.Unlock()
+
+	before := [game.SEATS]rating.Rating{}
+	for seat, id := range []string{first, second} {
+		change, err := s.repo.Rating(id)
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			before[seat] = rating.Default()
+		case err != nil:
+			return err
+		default:
+			before[seat] = change.Rating
+		}
+	}
+
+	after0, after1 := rating.Game(before[0], before[1], record.Winner == 0)
+	err := s.repo.AddRatings([]rating.Change{
+		{Account: first, Game: record.ID, At: record.Finished, Rating: after0},
+		{Account: second, Game: record.ID, At: record.Finished, Rating: after1},
+	})
+	if errors.Is(err, storage.ErrAlreadyRated) {
+		return nil
+	}
+	return err
+}
+
+func (s *Server) LeaderboardHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
+	ratings, err := s.repo.Ratings()
+	if err != nil {
+		return nil, err
+	}
+
+	standings := make([]html.Standing, 0, LEADERBOARD_SIZE)
+	for _, change := range ratings {
+		if len(standings) == LEADERBOARD_SIZE {
+			break
+		}
+
+		a, err := s.repo.Account(change.Account)
+		if err != nil {
+			return nil, err
+		}
+
+		// Guests have to register to be ranked
+		if a.Guest {
+			continue
+		}
+
+		standings = append(standings, html.Standing{Account: a, Rating: change.Rating})
+	}
+
+	return html.Leaderboard(locale(r), standings), nil
+}
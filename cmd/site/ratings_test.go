@@ -0,0 +1,60 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alfiehiscox/submarines/pkg/account"
+	"github.com/alfiehiscox/submarines/pkg/rating"
+	"github.com/alfiehiscox/submarines/pkg/storage"
+)
+
+func TestRatedGame(t *testing.T) {
+	repo := storage.NewMemory()
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{Repository: repo})
+	s.setUpRoutes()
+	ts := httptest.NewServer(s.mux)
+	defer ts.Close()
+
+	alpha, beta := account.NewBot("alpha"), account.NewBot("beta")
+	repo.SaveAccount(alpha)
+	repo.SaveAccount(beta)
+
+	s.matchmaker.EnqueueAs(alpha.Name, alpha.ID)
+	ticket, err := s.matchmaker.EnqueueAs(beta.Name, beta.ID)
+	if err != nil {
+		t.Fatalf("failed in set up: %s", err)
+	}
+
+	match := <-ticket.Matched()
+	match.Game.Resign(match.Seat)
+
+	// Also rated when the game is persisted, which must not count twice
+	for i := 0; i < 2; i++ {
+		if err := s.rate(match.Game); err != nil {
+			t.Fatalf("err should be nil: %s", err)
+		}
+	}
+
+	history, _ := repo.RatingHistory(alpha.ID)
+	if len(history) != 1 || history[0].Rating.Rating <= rating.INITIAL_RATING {
+		t.Fatalf("expected alpha to gain rating once, got %v", history)
+	}
+
+	b := newBrowser(t, ts.URL)
+	page := b.get("/leaderboard")
+	if strings.Index(page, "alpha") > strings.Index(page, "beta") || !strings.Contains(page, "beta") {
+		t.Fatal("expected alpha to be ranked above beta")
+	}
+
+	// Normally saved as it is played
+	repo.SaveGame(match.Game.Record())
+
+	page = b.get("/players/alpha")
+	if !strings.Contains(page, "<svg") || !strings.Contains(page, "Games played") {
+		t.Fatal("expected a profile with stats and a rating graph")
+	}
+}
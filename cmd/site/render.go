@@ -0,0 +1,43 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/alfiehiscox/submarines/pkg/html"
+	. "maragu.dev/gomponents"
+)
+
+// Whether r was made by htmx to swap part of the page it came from.
+// Boosted links and restoring history want whole pages.
+func isFragmentRequest(r *http.Request) bool {
+	return r.Header.Get("HX-Request") == "true" &&
+		r.Header.Get("HX-Boosted") != "true" &&
+		r.Header.Get("HX-History-Restore-Request") != "true"
+}
+
+// Returns fragment for htmx to swap into the page it is already on, or
+// page for anything else such as following a link or reloading, so every
+// URL makes sense on its own. A nil page lays out the fragment alone.
+func render(w http.ResponseWriter, r *http.Request, fragment, page Node) Node {
+	w.Header().Add("Vary", "HX-Request")
+
+	if isFragmentRequest(r) {
+		return fragment
+	}
+
+	if page == nil {
+		return html.Page(locale(r), fragment)
+	}
+	return page
+}
+
+// Has htmx add url to the browser's history once it swaps in the
+// response, so back and forward step through what was swapped in. With
+// replace the current entry is updated instead.
+func pushURL(w http.ResponseWriter, url string, replace bool) {
+	if replace {
+		w.Header().Set("HX-Replace-Url", url)
+		return
+	}
+	w.Header().Set("HX-Push-Url", url)
+}
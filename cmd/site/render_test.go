@@ -0,0 +1,79 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alfiehiscox/submarines/pkg/cell"
+	"github.com/alfiehiscox/submarines/pkg/storage"
+)
+
+func TestFragmentOrPage(t *testing.T) {
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{Repository: storage.NewMemory()})
+	s.setUpRoutes()
+	ts := httptest.NewServer(s.mux)
+	defer ts.Close()
+
+	get := func(path string, headers ...string) (*http.Response, string) {
+		t.Helper()
+
+		req, _ := http.NewRequest("GET", ts.URL+path, nil)
+		for i := 0; i < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %s", err)
+		}
+		defer res.Body.Close()
+
+		body, _ := io.ReadAll(res.Body)
+		return res, string(body)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		headers []string
+		page    bool
+	}{
+		{"navigation", nil, true},
+		{"htmx", []string{"HX-Request", "true"}, false},
+		{"boosted", []string{"HX-Request", "true", "HX-Boosted", "true"}, true},
+		{"history", []string{"HX-Request", "true", "HX-History-Restore-Request", "true"}, true},
+	} {
+		res, body := get("/ship-select/3", tc.headers...)
+		if page := strings.Contains(body, "<html"); page != tc.page || !strings.Contains(body, `id="ship-gallery"`) {
+			t.Fatalf("%s: expected a whole page to be %v, got %s", tc.name, tc.page, body)
+		}
+		if vary := res.Header.Get("Vary"); vary != "HX-Request" {
+			t.Fatalf("%s: expected responses to vary by HX-Request, got %q", tc.name, vary)
+		}
+	}
+
+	g, _ := s.games.Create()
+	for _, name := range []string{"alpha", "beta"} {
+		seat, _, _ := g.Join(name)
+		g.RandomizeFleet(seat)
+		g.Ready(seat)
+	}
+	g.Fire(0, cell.Coordinate{0, 0})
+	g.Resign(1)
+
+	replay := "/games/" + g.ID + "/replay"
+	if res, _ := get(replay+"/1", "HX-Request", "true"); res.Header.Get("HX-Push-Url") != replay+"?move=1" {
+		t.Fatalf("expected each step to be pushed to history, got %q", res.Header.Get("HX-Push-Url"))
+	}
+
+	if res, _ := get(replay+"/5?autoplay=true", "HX-Request", "true"); res.Header.Get("HX-Replace-Url") != replay+"?move=1" {
+		t.Fatalf("expected autoplay to replace the address, got %q", res.Header.Get("HX-Replace-Url"))
+	}
+
+	if _, body := get(replay + "/1"); !strings.Contains(body, "<html") || !strings.Contains(body, "Move 1 of 1") {
+		t.Fatal("expected a step loaded directly to be a whole page")
+	}
+}
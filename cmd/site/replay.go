@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/alfiehiscox/submarines/pkg/html"
+	"github.com/alfiehiscox/submarines/pkg/storage"
+	"github.com/alfiehiscox/submarines/pkg/view"
+	"github.com/go-chi/chi/v5"
+	. "maragu.dev/gomponents"
+)
+
+// Finished games listed in the history
+const HISTORY_SIZE = 50
+
+var errGameInProgress = &httpError{status: http.StatusConflict, key: "error.game_in_progress"}
+
+func (s *Server) HistoryHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
+	records, err := s.repo.Games()
+	if err != nil {
+		return nil, err
+	}
+
+	finished := make([]game.Record, 0, HISTORY_SIZE)
+	for _, record := range slices.Backward(records) {
+		if len(finished) == HISTORY_SIZE {
+			break
+		}
+		if record.Phase == game.FINISHED && record.Winner != game.NO_WINNER {
+			finished = append(finished, record)
+		}
+	}
+
+	return html.History(locale(r), finished), nil
+}
+
+func (s *Server) ReplayHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
+	n := 0
+	if move := r.URL.Query().Get("move"); move != "" {
+		var err error
+		if n, err = strconv.Atoi(move); err != nil {
+			return nil, badRequest("error.move", err)
+		}
+	}
+
+	v, err := s.replay(r, n)
+	if err != nil {
+		return nil, err
+	}
+
+	return html.Replay(locale(r), v, false), nil
+}
+
+// Renders the replay after the move in the url, for the replay's controls.
+// Each step is added to the browser's history, apart from those taken by
+// autoplay which only update the address.
+func (s *Server) ReplayMoveHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
+	n, err := strconv.Atoi(chi.URLParam(r, "move"))
+	if err != nil {
+		return nil, badRequest("error.move", err)
+	}
+
+	v, err := s.replay(r, n)
+	if err != nil {
+		return nil, err
+	}
+
+	autoplay := r.URL.Query().Get("autoplay") == "true"
+	pushURL(w, fmt.Sprintf("/games/%s/replay?move=%d", v.ID, v.N), autoplay)
+	return render(w, r, html.ReplayPanel(locale(r), v, autoplay), html.Replay(locale(r), v, autoplay)), nil
+}
+
+// Returns the replay of a finished game after its first n moves. Replays
+// show both fleets, so games still being played are refused.
+func (s *Server) replay(r *http.Request, n int) (view.Replay, error) {
+	id := chi.URLParam(r, "id")
+
+	var record game.Record
+	if g, ok := s.games.Get(id); ok {
+		record = g.Record()
+	} else {
+		var err error
+		record, err = s.repo.Game(id)
+		if errors.Is(err, storage.ErrNotFound) {
+			return view.Replay{}, errGameNotFound
+		}
+		if err != nil {
+			return view.Replay{}, err
+		}
+	}
+
+	v, ok := view.ForReplay(record, n)
+	if !ok {
+		return v, errGameInProgress
+	}
+	return v, nil
+}
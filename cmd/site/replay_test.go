@@ -0,0 +1,44 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alfiehiscox/submarines/pkg/cell"
+	"github.com/alfiehiscox/submarines/pkg/storage"
+)
+
+func TestReplay(t *testing.T) {
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{Repository: storage.NewMemory()})
+	s.setUpRoutes()
+	ts := httptest.NewServer(s.mux)
+	defer ts.Close()
+
+	g, _ := s.games.Create()
+	for _, name := range []string{"alpha", "beta"} {
+		seat, _, _ := g.Join(name)
+		g.RandomizeFleet(seat)
+		g.Ready(seat)
+	}
+	if _, err := g.Fire(0, cell.Coordinate{0, 0}); err != nil {
+		t.Fatalf("failed in set up: %s", err)
+	}
+
+	b := newBrowser(t, ts.URL)
+	if page := b.get("/games/" + g.ID + "/replay"); strings.Contains(page, "alpha vs beta") {
+		t.Fatal("expected an unfinished game not to be replayed")
+	}
+
+	g.Resign(1)
+
+	if page := b.get("/games/" + g.ID + "/replay"); !strings.Contains(page, "alpha vs beta") || !strings.Contains(page, "Play") {
+		t.Fatal("expected a replay of the finished game")
+	}
+
+	if page := b.get("/games/" + g.ID + "/replay/0?autoplay=true"); !strings.Contains(page, "Pause") {
+		t.Fatal("expected an autoplaying replay to offer to pause")
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alfiehiscox/submarines/pkg/html"
+)
+
+func TestSecureHeaders(t *testing.T) {
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{})
+	s.setUpRoutes()
+
+	ts := httptest.NewServer(s.mux)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/")
+	if err != nil {
+		t.Fatalf("request failed: %s", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+
+	for header, want := range map[string]string{
+		"X-Content-Type-Options": "nosniff",
+		"X-Frame-Options":        "DENY",
+		"Referrer-Policy":        "same-origin",
+	} {
+		if got := res.Header.Get(header); got != want {
+			t.Fatalf("expected %s to be %q, got %q", header, want, got)
+		}
+	}
+
+	csp := res.Header.Get("Content-Security-Policy")
+	for _, want := range append([]string{"script-src 'self'", "style-src 'self'", "frame-ancestors 'none'"}, html.ScriptSources()...) {
+		if !strings.Contains(csp, want) {
+			t.Fatalf("expected the policy to contain %s, got %q", want, csp)
+		}
+	}
+	if strings.Contains(csp, "unsafe") {
+		t.Fatalf("expected nothing unsafe to be allowed, got %q", csp)
+	}
+
+	if !strings.Contains(string(body), `name="htmx-config"`) {
+		t.Fatal("expected pages to configure htmx for the policy")
+	}
+
+	if hsts := res.Header.Get("Strict-Transport-Security"); hsts != "" {
+		t.Fatalf("expected no HSTS without TLS, got %q", hsts)
+	}
+
+	tls := httptest.NewTLSServer(s.mux)
+	defer tls.Close()
+
+	res, err = tls.Client().Get(tls.URL + "/healthz")
+	if err != nil {
+		t.Fatalf("request failed: %s", err)
+	}
+	res.Body.Close()
+
+	if hsts := res.Header.Get("Strict-Transport-Security"); hsts != HSTS {
+		t.Fatalf("expected HSTS over TLS, got %q", hsts)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/alfiehiscox/submarines/pkg/html"
+	"github.com/alfiehiscox/submarines/pkg/view"
+	. "maragu.dev/gomponents"
+)
+
+const (
+	// Events a spectator's stream can buffer before it is dropped
+	SPECTATOR_BUFFER = 64
+
+	MAX_SPECTATOR_DELAY = 10 * time.Minute
+)
+
+func (s *Server) WatchHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
+	live := s.games.Live()
+	snapshots := make([]game.Snapshot, len(live))
+	for i, g := range live {
+		snapshots[i] = g.Snapshot()
+	}
+	return html.Watch(locale(r), snapshots), nil
+}
+
+func (s *Server) SpectateHandler(w http.ResponseWriter, r *http.Request) (Node, error) {
+	g, err := s.game(r)
+	if err != nil {
+		return nil, err
+	}
+
+	delay, err := spectatorDelay(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return html.Spectate(locale(r), view.ForSpectator(g.SnapshotAt(time.Now().Add(-delay))), delay), nil
+}
+
+// Streams the public view of a game, optionally delayed so spectators
+// can't relay shots to a player in real time.
+func (s *Server) SpectateEventsHandler(w http.ResponseWriter, r *http.Request) {
+	g, err := s.game(r)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	delay, err := spectatorDelay(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	events, cancel := g.Subscribe(SPECTATOR_BUFFER)
+	defer cancel()
+	defer s.metrics.session("spectator")()
+
+	stream, err := newEventStream(w)
+	if err != nil {
+		s.logger(r).Error("Error opening event stream", "error", err)
+		return
+	}
+
+	ticker := time.NewTicker(CLOCK_INTERVAL)
+	defer ticker.Stop()
+
+	delayed := game.Delay(events, delay, r.Context().Done())
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-delayed:
+			if !ok {
+				return
+			}
+
+			if event.Type == game.CHAT {
+				chat := html.SpectatorChatLog(locale(r), g.SnapshotAt(time.Now().Add(-delay)))
+				if err := stream.Send("chat", chat); err != nil {
+					return
+				}
+				continue
+			}
+
+			snapshot := g.SnapshotAt(time.Now().Add(-delay))
+			if err := stream.Send("spectate", html.SpectatorPanel(locale(r), view.ForSpectator(snapshot))); err != nil {
+				return
+			}
+
+			if event.Type == game.FIRED || event.Type == game.ENDED {
+				if err := stream.Send("shot", html.Announcement(locale(r), snapshot, html.NO_SEAT)); err != nil {
+					return
+				}
+			}
+		case <-ticker.C:
+			if err := sendClocks(stream, locale(r), g.SnapshotAt(time.Now().Add(-delay))); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func spectatorDelay(r *http.Request) (time.Duration, error) {
+	value := r.URL.Query().Get("delay")
+	if value == "" {
+		return 0, nil
+	}
+
+	delay, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, badRequest("error.delay", err)
+	}
+
+	if delay < 0 || delay > MAX_SPECTATOR_DELAY {
+		return 0, badRequest("error.delay_range", nil)
+	}
+
+	return delay, nil
+}
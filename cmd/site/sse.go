@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	. "maragu.dev/gomponents"
+)
+
+// Writes server-sent events, each carrying a rendered fragment for the
+// htmx sse extension to swap in.
+type eventStream struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func newEventStream(w http.ResponseWriter) (*eventStream, error) {
+	rc := http.NewResponseController(w)
+
+	// Streams stay open far longer than the server's timeouts. The read
+	// deadline matters too, as running into it ends the request.
+	if err := rc.SetReadDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	if err := rc.Flush(); err != nil {
+		return nil, err
+	}
+
+	return &eventStream{w: w, rc: rc}, nil
+}
+
+func (s *eventStream) Send(event string, node Node) error {
+	var builder strings.Builder
+	if err := node.Render(&builder); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(s.w, "event: %s\n", event)
+	for _, line := range strings.Split(builder.String(), "\n") {
+		fmt.Fprintf(s.w, "data: %s\n", line)
+	}
+	fmt.Fprint(s.w, "\n")
+
+	return s.rc.Flush()
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alfiehiscox/submarines/pkg/cell"
+	"github.com/alfiehiscox/submarines/pkg/storage"
+)
+
+// How BoardCell draws a ship nobody has fired at
+const SHIP_CELL = `class="cell cell-ship"`
+
+func TestHiddenShipsNeverSent(t *testing.T) {
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{Repository: storage.NewMemory()})
+	s.setUpRoutes()
+	ts := httptest.NewServer(s.mux)
+	defer ts.Close()
+
+	b := newBrowser(t, ts.URL)
+	b.get("/")
+
+	res := b.post("/games", nil, true)
+	path := res.Header.Get("HX-Redirect")
+	g, ok := s.games.Get(strings.TrimPrefix(path, "/games/"))
+	if !ok {
+		t.Fatalf("expected a redirect to the new game, got %q", path)
+	}
+	g.Join("opponent")
+
+	// Before either fleet is ready the page shows only our own
+	g.RandomizeFleet(0)
+	g.RandomizeFleet(1)
+
+	ships := 0
+	for _, c := range g.Fleet(0) {
+		if c.Occupied {
+			ships++
+		}
+	}
+	if page := b.get(path); strings.Count(page, SHIP_CELL) != ships {
+		t.Fatalf("expected only our %d ships while placing, got %d", ships, strings.Count(page, SHIP_CELL))
+	}
+
+	g.Ready(0)
+	g.Ready(1)
+
+	// Seat 0 misses, then seat 1 hits one of seat 0's ships
+	for i, c := range g.Fleet(1) {
+		if !c.Occupied {
+			g.Fire(0, cell.Coordinate{i % cell.BOARD_WIDTH, i / cell.BOARD_WIDTH})
+			break
+		}
+	}
+	for i, c := range g.Fleet(0) {
+		if c.Occupied {
+			g.Fire(1, cell.Coordinate{i % cell.BOARD_WIDTH, i / cell.BOARD_WIDTH})
+			break
+		}
+	}
+
+	if page := b.get(path); strings.Count(page, SHIP_CELL) != ships-1 {
+		t.Fatalf("expected only our %d unhit ships on the page, got %d", ships-1, strings.Count(page, SHIP_CELL))
+	}
+
+	if page := newBrowser(t, ts.URL).get(path + "/watch"); strings.Count(page, SHIP_CELL) != 0 {
+		t.Fatal("expected spectators to see no ships")
+	}
+
+	// Panels pushed over the stream are the same
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL+path+"/events", nil)
+	stream, err := b.client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %s", err)
+	}
+	defer stream.Body.Close()
+
+	g.Resign(1)
+
+	panel := ""
+	scanner := bufio.NewScanner(stream.Body)
+	for event := ""; scanner.Scan(); {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case event == "game" && strings.HasPrefix(line, "data: "):
+			panel += strings.TrimPrefix(line, "data: ")
+		}
+		if event == "game" && line == "" {
+			break
+		}
+	}
+
+	if !strings.Contains(panel, "You won") {
+		t.Fatalf("expected the finished game's panel over the stream, got %q", panel)
+	}
+	if strings.Count(panel, SHIP_CELL) != ships-1 {
+		t.Fatalf("expected only our %d unhit ships in the stream, got %d", ships-1, strings.Count(panel, SHIP_CELL))
+	}
+}
+
+func TestQueueClosesOnceMatched(t *testing.T) {
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), Options{Repository: storage.NewMemory()})
+	s.setUpRoutes()
+	ts := httptest.NewServer(s.mux)
+	defer ts.Close()
+
+	b := newBrowser(t, ts.URL)
+	if page := b.get("/play"); !strings.Contains(page, `sse-close="matched"`) {
+		t.Fatal("expected the queue page to close its stream once matched")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	queue := func(b *browser) *http.Response {
+		t.Helper()
+
+		req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL+"/play/events", nil)
+		res, err := b.client.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %s", err)
+		}
+		return res
+	}
+
+	stream := queue(b)
+	defer stream.Body.Close()
+	for s.matchmaker.Len() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	other := queue(newBrowser(t, ts.URL))
+	defer other.Body.Close()
+
+	// The only event is the terminal one, and the stream ends after it
+	events := []string{}
+	data := ""
+	scanner := bufio.NewScanner(stream.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			events = append(events, strings.TrimPrefix(line, "event: "))
+		case strings.HasPrefix(line, "data: "):
+			data += strings.TrimPrefix(line, "data: ")
+		}
+	}
+
+	if len(events) != 1 || events[0] != "matched" {
+		t.Fatalf("expected a single matched event, got %v", events)
+	}
+	if !strings.Contains(data, "/resume/") {
+		t.Fatalf("expected the matched event to take the player to their seat, got %q", data)
+	}
+	if s.matchmaker.Len() != 0 {
+		t.Fatalf("expected nobody left queued, got %d", s.matchmaker.Len())
+	}
+}
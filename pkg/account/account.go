@@ -0,0 +1,152 @@
+// Package account holds the people who play games: registered accounts,
+// guests that can later register, and the sessions they log in with.
+package account
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	MIN_PASSWORD = 8
+
+	// bcrypt ignores anything longer
+	MAX_PASSWORD = 72
+
+	SESSION_LIFETIME = 30 * 24 * time.Hour
+)
+
+var (
+	ErrInvalidName     = errors.New("names are 3 to 20 letters, numbers, - or _")
+	ErrInvalidPassword = fmt.Errorf("passwords are %d to %d characters", MIN_PASSWORD, MAX_PASSWORD)
+	ErrWrongPassword   = errors.New("wrong name or password")
+	ErrRegistered      = errors.New("already registered")
+)
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9_-]{3,20}$`)
+
+// Account is a person or bot who plays games. Guests have a generated
+// name and no password until they Register. Bots authenticate with keys
+// and never have a password.
+type Account struct {
+	ID           string
+	Name         string
+	PasswordHash []byte
+	Guest        bool
+	Bot          bool
+	Created      time.Time
+}
+
+// Session logs a browser in to an account. Only the hash of the session
+// token is kept, like seat tokens.
+type Session struct {
+	TokenHash string
+	Account   string
+	Expires   time.Time
+}
+
+// Returns a new guest account.
+func NewGuest() (Account, error) {
+	id, err := newToken()
+	if err != nil {
+		return Account{}, err
+	}
+
+	return Account{
+		ID:      id,
+		Name:    "Guest-" + id[:6],
+		Guest:   true,
+		Created: time.Now(),
+	}, nil
+}
+
+// Returns the account of the bot with name.
+func NewBot(name string) Account {
+	return Account{
+		ID:      "bot-" + name,
+		Name:    name,
+		Bot:     true,
+		Created: time.Now(),
+	}
+}
+
+// Gives a guest a name and password, keeping its id so its games stay
+// with it.
+func (a *Account) Register(name, password string) error {
+	if !a.Guest {
+		return ErrRegistered
+	}
+
+	if !validName.MatchString(name) {
+		return ErrInvalidName
+	}
+
+	if len(password) < MIN_PASSWORD || len(password) > MAX_PASSWORD {
+		return ErrInvalidPassword
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	a.Name = name
+	a.PasswordHash = hash
+	a.Guest = false
+	return nil
+}
+
+// Compared against when there is no account, so a login takes as long
+// whether or not the name exists.
+var noAccount, _ = bcrypt.GenerateFromPassword([]byte("no account"), bcrypt.DefaultCost)
+
+// Checks password against the account. a may be nil when no account has
+// the name being logged in with.
+func CheckPassword(a *Account, password string) error {
+	hash := noAccount
+	canLogIn := a != nil && !a.Guest && !a.Bot
+	if canLogIn {
+		hash = a.PasswordHash
+	}
+
+	if err := bcrypt.CompareHashAndPassword(hash, []byte(password)); err != nil || !canLogIn {
+		return ErrWrongPassword
+	}
+
+	return nil
+}
+
+// Starts a session for the account, returning the token to give the
+// browser.
+func NewSession(a Account) (Session, string, error) {
+	token, err := newToken()
+	if err != nil {
+		return Session{}, "", err
+	}
+
+	return Session{
+		TokenHash: HashToken(token),
+		Account:   a.ID,
+		Expires:   time.Now().Add(SESSION_LIFETIME),
+	}, token, nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
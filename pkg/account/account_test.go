@@ -0,0 +1,51 @@
+package account
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+	a, err := NewGuest()
+	if err != nil {
+		t.Fatalf("failed in set up: %s", err)
+	}
+
+	if err := CheckPassword(&a, ""); !errors.Is(err, ErrWrongPassword) {
+		t.Fatalf("guests should not be able to log in, got %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		err      error
+	}{
+		{"ab", "long enough", ErrInvalidName},
+		{"has space", "long enough", ErrInvalidName},
+		{"admiral", "short", ErrInvalidPassword},
+		{"admiral", "long enough", nil},
+		{"admiral", "long enough", ErrRegistered},
+	}
+
+	for _, test := range tests {
+		if err := a.Register(test.name, test.password); !errors.Is(err, test.err) {
+			t.Fatalf("register %q: expected %v, got %v", test.name, test.err, err)
+		}
+	}
+
+	if a.Guest || a.Name != "admiral" {
+		t.Fatalf("expected a registered account, got %+v", a)
+	}
+
+	if err := CheckPassword(&a, "long enough"); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	if err := CheckPassword(&a, "wrong password"); !errors.Is(err, ErrWrongPassword) {
+		t.Fatalf("expected ErrWrongPassword, got %v", err)
+	}
+
+	if err := CheckPassword(nil, "long enough"); !errors.Is(err, ErrWrongPassword) {
+		t.Fatalf("expected ErrWrongPassword, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+// Package api holds the JSON types spoken by the /api/v1 routes of the
+// site, shared by the server and its clients.
+package api
+
+import (
+	"time"
+)
+
+const (
+	// Seat tokens are sent as "Authorization: Bearer <token>"
+	AUTH_SCHEME = "Bearer"
+
+	INVALID_REQUEST    ErrorCode = "invalid_request"
+	INVALID_COORDINATE ErrorCode = "invalid_coordinate"
+	INVALID_FLEET      ErrorCode = "invalid_fleet"
+	UNAUTHORIZED       ErrorCode = "unauthorized"
+	NOT_FOUND          ErrorCode = "not_found"
+	GAME_FULL          ErrorCode = "game_full"
+	WRONG_PHASE        ErrorCode = "wrong_phase"
+	NOT_YOUR_TURN      ErrorCode = "not_your_turn"
+	ALREADY_FIRED      ErrorCode = "already_fired"
+	NAME_TAKEN         ErrorCode = "name_taken"
+	UNAVAILABLE        ErrorCode = "unavailable"
+	RATE_LIMITED       ErrorCode = "rate_limited"
+	BANNED             ErrorCode = "banned"
+	INTERNAL           ErrorCode = "internal"
+)
+
+type ErrorCode string
+
+// Every non-2xx response has this body.
+type ErrorResponse struct {
+	Error Error `json:"error"`
+}
+
+type Error struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+func (e Error) Error() string {
+	return string(e.Code) + ": " + e.Message
+}
+
+type Coordinate struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type JoinRequest struct {
+	Name string `json:"name"`
+}
+
+// Returned when creating or joining a game. Keep the token secret, it
+// is the only proof of owning the seat.
+type JoinResponse struct {
+	Game  Game   `json:"game"`
+	Seat  int    `json:"seat"`
+	Token string `json:"token"`
+}
+
+type Ship struct {
+	Size        int    `json:"size"`
+	Orientation string `json:"orientation"`
+	Coordinate
+}
+
+// Either Ships or Random must be set.
+type FleetRequest struct {
+	Ships  []Ship `json:"ships,omitempty"`
+	Random bool   `json:"random,omitempty"`
+}
+
+type ShotRequest struct {
+	Coordinate
+}
+
+type Move struct {
+	Seat int       `json:"seat"`
+	Hit  bool      `json:"hit"`
+	At   time.Time `json:"at"`
+	Coordinate
+}
+
+type MovesResponse struct {
+	Moves []Move `json:"moves"`
+}
+
+type Player struct {
+	Seat   int    `json:"seat"`
+	Name   string `json:"name"`
+	Joined bool   `json:"joined"`
+	Placed bool   `json:"placed"`
+	Ready  bool   `json:"ready"`
+
+	// Time left on the seat's clock, only set for games with a clock
+	ClockMs *int64 `json:"clock_ms,omitempty"`
+}
+
+// A game from the point of view of whoever asked for it. Seat and Fleet
+// are only set when the request carried a seat token.
+type Game struct {
+	ID        string       `json:"id"`
+	Phase     string       `json:"phase"`
+	Turn      int          `json:"turn"`
+	Winner    int          `json:"winner"`
+	Forfeited bool         `json:"forfeited"`
+	Players   []Player     `json:"players"`
+	Moves     []Move       `json:"moves"`
+	Seat      *int         `json:"seat,omitempty"`
+	Fleet     []Coordinate `json:"fleet,omitempty"`
+
+	// When the seat to fire runs out of time, only set while a timed
+	// game is being played
+	Deadline *time.Time `json:"deadline,omitempty"`
+}
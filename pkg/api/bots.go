@@ -0,0 +1,62 @@
+package api
+
+import (
+	"time"
+)
+
+// Messages exchanged over the bot gateway websocket at /api/v1/bots.
+// Every message is a JSON object with a "type" and the fields relevant
+// to it.
+const (
+	// Bot to server
+	QUEUE     MessageType = "queue"
+	JOIN      MessageType = "join"
+	PLACEMENT MessageType = "placement"
+	FIRE      MessageType = "fire"
+
+	// Server to bot
+	WELCOME           MessageType = "welcome"
+	MATCHED           MessageType = "matched"
+	PLACEMENT_REQUEST MessageType = "placement_request"
+	YOUR_TURN         MessageType = "your_turn"
+	SHOT_RESULT       MessageType = "shot_result"
+	GAME_OVER         MessageType = "game_over"
+	ERROR             MessageType = "error"
+)
+
+type MessageType string
+
+type Message struct {
+	Type MessageType `json:"type"`
+
+	// join: the game to take a seat in
+	// matched: the game that was started
+	Game string `json:"game,omitempty"`
+
+	// welcome: the bot's name
+	// matched: the opponent's name
+	Name string `json:"name,omitempty"`
+
+	// matched: the bot's seat
+	// game_over: the winning seat
+	Seat *int `json:"seat,omitempty"`
+
+	// placement: the fleet, unless Random is set
+	Ships  []Ship `json:"ships,omitempty"`
+	Random bool   `json:"random,omitempty"`
+
+	// fire: where to shoot
+	Coordinate *Coordinate `json:"coordinate,omitempty"`
+
+	// shot_result: a shot by either seat
+	Move *Move `json:"move,omitempty"`
+
+	// placement_request and your_turn: act before this or forfeit
+	Deadline *time.Time `json:"deadline,omitempty"`
+
+	// game_over: whether the game ended by forfeit
+	Forfeited bool `json:"forfeited,omitempty"`
+
+	// error: what went wrong with the last message
+	Error *Error `json:"error,omitempty"`
+}
@@ -14,10 +14,26 @@ const (
 
 	HORIZONTAL Orientation = "HORIZONTAL"
 	VERTICAL   Orientation = "VERTICAL"
+
+	UNKNOWN State = "UNKNOWN"
+	MISS    State = "MISS"
+	HIT     State = "HIT"
+
+	// A ship nobody has fired at yet. Only ever shown to the fleet's
+	// owner, or once the game is over.
+	SHIP State = "SHIP"
+)
+
+var (
+	ErrOutOfBounds = errors.New("out of bounds")
+	ErrOffBoard    = errors.New("off the board")
 )
 
 type Orientation string
 
+// What is known about a single cell of a fleet's board
+type State string
+
 // Coordinates are zero based, and therefore
 // - x  is between 0 and BOARD_WIDHT - 1
 // - y  is between 0 and BOARD_HEIGHT - 1
@@ -29,11 +45,11 @@ func (c Coordinate) ToIndex() int {
 
 func NewCoordinate(x, y int) (Coordinate, error) {
 	if x < 0 || x >= BOARD_WIDTH {
-		return Coordinate{}, errors.New(fmt.Sprintf("x value %d out of bounds", x))
+		return Coordinate{}, fmt.Errorf("x value %d %w", x, ErrOutOfBounds)
 	}
 
 	if y < 0 || y >= BOARD_HEIGHT {
-		return Coordinate{}, errors.New(fmt.Sprintf("y value %d out of bounds", y))
+		return Coordinate{}, fmt.Errorf("y value %d %w", y, ErrOutOfBounds)
 	}
 
 	return Coordinate{x, y}, nil
@@ -60,23 +76,19 @@ func GetRandomCoord(size int) Coordinate {
 
 func VerifyCoordinate(size int, orientation Orientation, coord Coordinate) error {
 	if coord[0] < 0 || coord[1] < 0 {
-		msg := fmt.Sprintf("Carrier at %v [%s] is off the board", coord, orientation)
-		return errors.New(msg)
+		return fmt.Errorf("Carrier at %v [%s] is %w", coord, orientation, ErrOffBoard)
 	}
 
 	if coord[0] > BOARD_WIDTH || coord[1] > BOARD_HEIGHT {
-		msg := fmt.Sprintf("Carrier at %v [%s] is off the board", coord, orientation)
-		return errors.New(msg)
+		return fmt.Errorf("Carrier at %v [%s] is %w", coord, orientation, ErrOffBoard)
 	}
 
 	if orientation == HORIZONTAL && coord[0] > BOARD_WIDTH-size {
-		msg := fmt.Sprintf("Carrier at %v [%s] is off the board", coord, orientation)
-		return errors.New(msg)
+		return fmt.Errorf("Carrier at %v [%s] is %w", coord, orientation, ErrOffBoard)
 	}
 
 	if orientation == VERTICAL && coord[1] > BOARD_HEIGHT-size {
-		msg := fmt.Sprintf("Carrier at %v [%s] is off the board", coord, orientation)
-		return errors.New(msg)
+		return fmt.Errorf("Carrier at %v [%s] is %w", coord, orientation, ErrOffBoard)
 	}
 
 	return nil
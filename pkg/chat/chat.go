@@ -0,0 +1,69 @@
+// Package chat moderates what players say to each other in games.
+package chat
+
+import (
+	"bufio"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Filter masks unwanted words in chat messages. A nil Filter lets
+// everything through.
+type Filter struct {
+	pattern *regexp.Regexp
+}
+
+// Returns a filter masking each of words wherever it appears as a whole
+// word, ignoring case.
+func NewFilter(words []string) *Filter {
+	quoted := make([]string, 0, len(words))
+	for _, word := range words {
+		if word = strings.TrimSpace(word); word != "" {
+			quoted = append(quoted, regexp.QuoteMeta(word))
+		}
+	}
+
+	if len(quoted) == 0 {
+		return nil
+	}
+
+	return &Filter{pattern: regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`)}
+}
+
+// Reads a filter from a file with a word per line. Blank lines and lines
+// starting with # are ignored.
+func LoadFilter(path string) (*Filter, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			words = append(words, line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewFilter(words), nil
+}
+
+// Replaces every filtered word in text with asterisks.
+func (f *Filter) Clean(text string) string {
+	if f == nil {
+		return text
+	}
+
+	return f.pattern.ReplaceAllStringFunc(text, func(word string) string {
+		return strings.Repeat("*", utf8.RuneCountInString(word))
+	})
+}
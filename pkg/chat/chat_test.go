@@ -0,0 +1,38 @@
+package chat
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClean(t *testing.T) {
+	f := NewFilter([]string{"darn", "heck"})
+
+	got := f.Clean("Darn it, what the HECK. Darned heckler")
+	want := "**** it, what the ****. Darned heckler"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	var none *Filter
+	if got := none.Clean("darn"); got != "darn" {
+		t.Fatalf("expected a nil filter to change nothing, got %q", got)
+	}
+}
+
+func TestLoadFilter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(path, []byte("# Words to mask\n\ndarn\n"), 0600); err != nil {
+		t.Fatalf("failed in set up: %s", err)
+	}
+
+	f, err := LoadFilter(path)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	if got := f.Clean("darn"); got != "****" {
+		t.Fatalf("expected the word to be masked, got %q", got)
+	}
+}
@@ -0,0 +1,138 @@
+// Package client is a typed client for the site's JSON API, as described
+// by /api/openapi.json.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/alfiehiscox/submarines/pkg/api"
+)
+
+// Client talks to a single server on behalf of at most one seat.
+// Errors returned by the server are api.Error values.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+
+	// Seat token sent with every request. Set by CreateGame and
+	// JoinGame, or directly to resume a seat.
+	Token string
+}
+
+// baseURL is the server's root, for example "http://localhost:8080".
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/") + "/api/v1",
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// Creates a game, taking its first seat.
+func (c *Client) CreateGame(ctx context.Context, name string) (api.JoinResponse, error) {
+	var res api.JoinResponse
+	err := c.do(ctx, http.MethodPost, "/games", api.JoinRequest{Name: name}, &res)
+	if err == nil {
+		c.Token = res.Token
+	}
+	return res, err
+}
+
+// Takes the free seat in game id.
+func (c *Client) JoinGame(ctx context.Context, id, name string) (api.JoinResponse, error) {
+	var res api.JoinResponse
+	err := c.do(ctx, http.MethodPost, gamePath(id, "/join"), api.JoinRequest{Name: name}, &res)
+	if err == nil {
+		c.Token = res.Token
+	}
+	return res, err
+}
+
+func (c *Client) Game(ctx context.Context, id string) (api.Game, error) {
+	var res api.Game
+	err := c.do(ctx, http.MethodGet, gamePath(id, ""), nil, &res)
+	return res, err
+}
+
+func (c *Client) PlaceFleet(ctx context.Context, id string, ships []api.Ship) (api.Game, error) {
+	var res api.Game
+	err := c.do(ctx, http.MethodPut, gamePath(id, "/fleet"), api.FleetRequest{Ships: ships}, &res)
+	return res, err
+}
+
+func (c *Client) RandomFleet(ctx context.Context, id string) (api.Game, error) {
+	var res api.Game
+	err := c.do(ctx, http.MethodPut, gamePath(id, "/fleet"), api.FleetRequest{Random: true}, &res)
+	return res, err
+}
+
+func (c *Client) Ready(ctx context.Context, id string) (api.Game, error) {
+	var res api.Game
+	err := c.do(ctx, http.MethodPost, gamePath(id, "/ready"), nil, &res)
+	return res, err
+}
+
+func (c *Client) Fire(ctx context.Context, id string, x, y int) (api.Game, error) {
+	var res api.Game
+	req := api.ShotRequest{Coordinate: api.Coordinate{X: x, Y: y}}
+	err := c.do(ctx, http.MethodPost, gamePath(id, "/shots"), req, &res)
+	return res, err
+}
+
+// Lists the moves of game id after the first since.
+func (c *Client) Moves(ctx context.Context, id string, since int) ([]api.Move, error) {
+	var res api.MovesResponse
+	path := gamePath(id, "/moves") + "?since=" + strconv.Itoa(since)
+	err := c.do(ctx, http.MethodGet, path, nil, &res)
+	return res.Moves, err
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", api.AUTH_SCHEME+" "+c.Token)
+	}
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		var apiErr api.ErrorResponse
+		if err := json.NewDecoder(res.Body).Decode(&apiErr); err != nil {
+			return fmt.Errorf("%s %s: unexpected status %d", method, path, res.StatusCode)
+		}
+		return apiErr.Error
+	}
+
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+func gamePath(id, suffix string) string {
+	return "/games/" + url.PathEscape(id) + suffix
+}
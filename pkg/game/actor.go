@@ -0,0 +1,26 @@
+package game
+
+// Actor takes actions in a game on behalf of a request, such as an HTTP
+// request, marking the events they publish with its ID so logs can tie
+// the two together.
+type Actor struct {
+	game    *Game
+	request string
+}
+
+// Returns an Actor for the request with the given ID. The Game's own
+// methods act for no request in particular.
+func (g *Game) For(request string) Actor {
+	return Actor{game: g, request: request}
+}
+
+// Locks the game for an action on behalf of request, see Actor.
+func (g *Game) lock(request string) {
+	g.mu.Lock()
+	g.request = request
+}
+
+func (g *Game) unlock() {
+	g.request = ""
+	g.mu.Unlock()
+}
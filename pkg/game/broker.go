@@ -0,0 +1,145 @@
+package game
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	JOINED  EventType = "JOINED"
+	PLACED  EventType = "PLACED"
+	READY   EventType = "READY"
+	STARTED EventType = "STARTED"
+	FIRED   EventType = "FIRED"
+	ENDED   EventType = "ENDED"
+
+	DISCONNECTED EventType = "DISCONNECTED"
+	RECONNECTED  EventType = "RECONNECTED"
+
+	// A seat took too long to fire, see TimeControl
+	TIMED_OUT EventType = "TIMED_OUT"
+
+	CHAT     EventType = "CHAT"
+	REPORTED EventType = "REPORTED"
+
+	// The server is shutting down, see Game.Suspend
+	SUSPENDED EventType = "SUSPENDED"
+)
+
+type EventType string
+
+// Something that happened in a game. Events only carry information
+// that is public to both seats and any spectators.
+type Event struct {
+	Type    EventType
+	Game    string
+	Seat    int
+	Move    *Move
+	Message *Message
+	At      time.Time
+
+	// ID of the request that caused the event, empty for those that
+	// happen by themselves such as timeouts. See Actor.
+	Request string
+}
+
+// Broker fans events out to any number of subscribers. Publishing
+// never blocks: a subscriber that falls behind is dropped and its
+// channel closed, so it can resubscribe and catch up from a snapshot.
+type Broker struct {
+	mu   sync.Mutex
+	subs map[chan Event]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{subs: make(map[chan Event]struct{})}
+}
+
+// Returns a channel receiving every event published from now on and a
+// function to cancel the subscription.
+func (b *Broker) Subscribe(buffer int) (<-chan Event, func()) {
+	ch := make(chan Event, buffer)
+
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+
+	return ch, cancel
+}
+
+func (b *Broker) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs {
+		select {
+		case ch <- event:
+		default:
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Returns the number of live subscriptions.
+func (b *Broker) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+// Re-emits events from in once they are at least d old. Events are taken
+// off in as soon as they arrive and held here while they wait, so a
+// subscription isn't dropped for filling up over a long delay. The
+// returned channel is closed once in is closed and everything held has
+// been sent, or when done is.
+func Delay(in <-chan Event, d time.Duration, done <-chan struct{}) <-chan Event {
+	if d <= 0 {
+		return in
+	}
+
+	out := make(chan Event, cap(in))
+	go func() {
+		defer close(out)
+
+		var pending []Event
+		for in != nil || len(pending) > 0 {
+			var due <-chan time.Time
+			var send chan<- Event
+			var next Event
+			if len(pending) > 0 {
+				next = pending[0]
+				if wait := time.Until(next.At.Add(d)); wait > 0 {
+					due = time.After(wait)
+				} else {
+					send = out
+				}
+			}
+
+			select {
+			case event, ok := <-in:
+				if !ok {
+					in = nil
+					continue
+				}
+				pending = append(pending, event)
+			case <-due:
+			case send <- next:
+				pending = pending[1:]
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,128 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// Longest message, in characters
+	MAX_MESSAGE = 200
+
+	// A seat can send CHAT_BURST messages every CHAT_WINDOW
+	CHAT_BURST  = 5
+	CHAT_WINDOW = 10 * time.Second
+)
+
+var (
+	ErrEmptyMessage   = errors.New("message is empty")
+	ErrMessageTooLong = fmt.Errorf("messages are at most %d characters", MAX_MESSAGE)
+	ErrChatTooFast    = errors.New("sending messages too quickly, wait a moment")
+	ErrChatClosed     = errors.New("chat has closed")
+)
+
+// Something a seat said in the game's chat, after filtering.
+type Message struct {
+	Seat int
+	Text string
+	At   time.Time
+}
+
+// A seat flagging its opponent's messages for a moderator to look at.
+type Report struct {
+	Seat int
+	At   time.Time
+}
+
+// Posts text to the game's chat on behalf of seat. Only seated players
+// can chat, and only until the game finishes.
+func (g *Game) Say(seat int, text string) (Message, error) {
+	return g.For("").Say(seat, text)
+}
+
+// See Game.Say.
+func (a Actor) Say(seat int, text string) (Message, error) {
+	g := a.game
+	g.lock(a.request)
+	defer g.unlock()
+
+	if seat < 0 || seat >= SEATS || g.players[seat] == nil {
+		return Message{}, ErrUnknownSeat
+	}
+
+	if g.suspended {
+		return Message{}, ErrSuspended
+	}
+
+	if g.phase == FINISHED {
+		return Message{}, ErrChatClosed
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Message{}, ErrEmptyMessage
+	}
+
+	if utf8.RuneCountInString(text) > MAX_MESSAGE {
+		return Message{}, ErrMessageTooLong
+	}
+
+	now := time.Now()
+	recent := 0
+	for i := len(g.chat) - 1; i >= 0 && now.Sub(g.chat[i].At) < CHAT_WINDOW; i-- {
+		if g.chat[i].Seat == seat {
+			recent++
+		}
+	}
+	if recent >= CHAT_BURST {
+		return Message{}, ErrChatTooFast
+	}
+
+	message := Message{Seat: seat, Text: g.ChatFilter.Clean(text), At: now}
+	g.chat = append(g.chat, message)
+	g.publish(Event{Type: CHAT, Seat: seat, Message: &message, At: now})
+	return message, nil
+}
+
+// Hides the opponent's messages from seat, or shows them again.
+func (g *Game) Mute(seat int, muted bool) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if seat < 0 || seat >= SEATS || g.players[seat] == nil {
+		return ErrUnknownSeat
+	}
+
+	g.muted[seat] = muted
+	return nil
+}
+
+// Reports the opponent's messages on behalf of seat. Each seat can only
+// report once, later reports are ignored.
+func (g *Game) Report(seat int) error {
+	return g.For("").Report(seat)
+}
+
+// See Game.Report.
+func (a Actor) Report(seat int) error {
+	g := a.game
+	g.lock(a.request)
+	defer g.unlock()
+
+	if seat < 0 || seat >= SEATS || g.players[seat] == nil {
+		return ErrUnknownSeat
+	}
+
+	for _, report := range g.reports {
+		if report.Seat == seat {
+			return nil
+		}
+	}
+
+	g.reports = append(g.reports, Report{Seat: seat, At: time.Now()})
+	g.publish(Event{Type: REPORTED, Seat: seat})
+	return nil
+}
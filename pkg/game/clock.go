@@ -0,0 +1,143 @@
+package game
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/alfiehiscox/submarines/pkg/cell"
+)
+
+const (
+	// Fire at a random cell on the seat's behalf and carry on
+	RANDOM_SHOT Timeout = "RANDOM_SHOT"
+
+	// End the game in favour of the other seat
+	FORFEIT Timeout = "FORFEIT"
+)
+
+// What happens to a seat that takes longer than MoveTime to fire.
+type Timeout string
+
+// TimeControl limits how long seats can take over their shots. Either
+// limit can be zero for none.
+type TimeControl struct {
+	// Time allowed for each shot
+	MoveTime time.Duration
+
+	// Time each seat has for all of its shots, like a chess clock,
+	// with Increment added back after every shot
+	Clock     time.Duration
+	Increment time.Duration
+
+	// What happens when MoveTime runs out. Running out of Clock always
+	// loses the game.
+	OnTimeout Timeout
+}
+
+func (tc TimeControl) Enabled() bool {
+	return tc.MoveTime > 0 || tc.Clock > 0
+}
+
+// Starts timing the seat whose turn it is. Callers must hold g.mu.
+func (g *Game) startTurn() {
+	if g.phase != PLAYING || g.suspended || !g.TimeControl.Enabled() {
+		return
+	}
+
+	g.turnStarted = time.Now()
+	seat, moves := g.turn, len(g.moves)
+	g.turnTimer = time.AfterFunc(time.Until(g.deadline()), func() {
+		g.timeout(seat, moves)
+	})
+}
+
+// Stops timing the current turn, taking the time spent off the seat's
+// clock. Callers must hold g.mu.
+func (g *Game) stopTurn() {
+	if g.turnTimer != nil {
+		g.turnTimer.Stop()
+		g.turnTimer = nil
+	}
+
+	if g.turnStarted.IsZero() {
+		return
+	}
+
+	g.clocks = g.remaining()
+	g.turnStarted = time.Time{}
+}
+
+// Returns the time left on each seat's clock, counting the turn in
+// progress. Callers must hold g.mu.
+func (g *Game) remaining() [SEATS]time.Duration {
+	clocks := g.clocks
+	if g.TimeControl.Clock > 0 && !g.turnStarted.IsZero() {
+		clocks[g.turn] = max(clocks[g.turn]-time.Since(g.turnStarted), 0)
+	}
+	return clocks
+}
+
+// Returns when the current turn runs out of time, or zero if it can't.
+// Callers must hold g.mu.
+func (g *Game) deadline() time.Time {
+	if g.turnStarted.IsZero() {
+		return time.Time{}
+	}
+
+	var deadline time.Time
+	if g.TimeControl.MoveTime > 0 {
+		deadline = g.turnStarted.Add(g.TimeControl.MoveTime)
+	}
+
+	if g.TimeControl.Clock > 0 {
+		flag := g.turnStarted.Add(g.clocks[g.turn])
+		if deadline.IsZero() || flag.Before(deadline) {
+			deadline = flag
+		}
+	}
+
+	return deadline
+}
+
+// Called when seat runs out of time for the shot after the given number
+// of moves. Does nothing if it has fired since.
+func (g *Game) timeout(seat, moves int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.phase != PLAYING || g.suspended || g.turn != seat || len(g.moves) != moves {
+		return
+	}
+
+	g.turnTimer = nil
+	g.publish(Event{Type: TIMED_OUT, Seat: seat})
+
+	flagged := g.TimeControl.Clock > 0 && g.remaining()[seat] == 0
+	if flagged || g.TimeControl.OnTimeout != RANDOM_SHOT {
+		g.end(1-seat, true)
+		return
+	}
+
+	g.fire(seat, g.randomTarget(seat))
+}
+
+// Picks a cell seat hasn't fired at yet. Callers must hold g.mu.
+func (g *Game) randomTarget(seat int) cell.Coordinate {
+	fired := make([]bool, cell.BOARD_WIDTH*cell.BOARD_HEIGHT)
+	for _, move := range g.moves {
+		if move.Seat == seat {
+			fired[move.Coordinate.ToIndex()] = true
+		}
+	}
+
+	var open []int
+	for i := range fired {
+		if !fired[i] {
+			open = append(open, i)
+		}
+	}
+
+	// The game ends before a seat runs out of cells to fire at
+	i := open[rand.IntN(len(open))]
+	return cell.Coordinate{i % cell.BOARD_WIDTH, i / cell.BOARD_WIDTH}
+}
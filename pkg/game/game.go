@@ -0,0 +1,495 @@
+package game
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/alfiehiscox/submarines/pkg/board"
+	"github.com/alfiehiscox/submarines/pkg/cell"
+	"github.com/alfiehiscox/submarines/pkg/chat"
+	"github.com/alfiehiscox/submarines/pkg/player"
+)
+
+const (
+	SEATS = 2
+
+	// No seat has won (yet)
+	NO_WINNER = -1
+
+	PLACING  Phase = "PLACING"
+	PLAYING  Phase = "PLAYING"
+	FINISHED Phase = "FINISHED"
+)
+
+var (
+	ErrGameFull      = errors.New("game is full")
+	ErrUnknownSeat   = errors.New("unknown seat")
+	ErrWrongPhase    = errors.New("action not allowed in this phase")
+	ErrNotYourTurn   = errors.New("not your turn")
+	ErrAlreadyFired  = errors.New("coordinate already fired at")
+	ErrAlreadyReady  = errors.New("fleet already locked in")
+	ErrFleetNotReady = errors.New("fleet has not been placed")
+	ErrInvalidFleet  = errors.New("invalid fleet")
+	ErrSuspended     = errors.New("game is suspended while the server restarts")
+)
+
+// Sizes of the ships every fleet is made of
+var FLEET = []int{5, 4, 3, 3, 2}
+
+type Phase string
+
+// A ship placed with its top left corner at Coordinate.
+type Ship struct {
+	Size        int
+	Orientation cell.Orientation
+	Coordinate  cell.Coordinate
+}
+
+// A single shot, fired by Seat at the other seat's fleet.
+type Move struct {
+	Seat       int
+	Coordinate cell.Coordinate
+	Hit        bool
+	At         time.Time
+}
+
+// Game is a single match between two seats. All methods are safe
+// for concurrent use, state is read through Snapshot.
+type Game struct {
+	ID      string
+	Created time.Time
+
+	// How long a disconnected seat has to come back before forfeiting.
+	// Zero means never forfeit.
+	ForfeitTimeout time.Duration
+
+	// Whether the result counts towards the ratings of the seats'
+	// accounts. Only set before the game is shared.
+	Rated bool
+
+	// Limits on how long each seat can take to fire. Only set before
+	// the game is shared.
+	TimeControl TimeControl
+
+	// Masks unwanted words in chat messages, nil to allow anything
+	ChatFilter *chat.Filter
+
+	// Called with every event as it is published, such as to count
+	// moves. It runs while the game is locked, so it must be quick and
+	// must not call back into the game. Only set before the game is
+	// shared.
+	OnEvent func(Event)
+
+	mu        sync.Mutex
+	players   [SEATS]*player.Player
+	tokens    [SEATS]string // hashed, see hashToken
+	accounts  [SEATS]string // empty for anonymous seats
+	placed    [SEATS]bool
+	ready     [SEATS]bool
+	phase     Phase
+	turn      int
+	winner    int
+	moves     []Move
+	finished  time.Time
+	forfeited bool
+	suspended bool
+
+	seen        [SEATS]bool
+	connections [SEATS]int
+	away        [SEATS]time.Time
+	forfeits    [SEATS]*time.Timer
+
+	clocks      [SEATS]time.Duration
+	turnStarted time.Time // zero while no turn is being timed
+	turnTimer   *time.Timer
+
+	chat    []Message
+	muted   [SEATS]bool
+	reports []Report
+
+	// ID of the request the game is locked for, see Actor
+	request string
+
+	broker *Broker
+}
+
+func New(id string) *Game {
+	return &Game{
+		ID:      id,
+		Created: time.Now(),
+		phase:   PLACING,
+		winner:  NO_WINNER,
+		broker:  NewBroker(),
+	}
+}
+
+// Subscribe to the events of this game. See Broker.Subscribe.
+func (g *Game) Subscribe(buffer int) (<-chan Event, func()) {
+	return g.broker.Subscribe(buffer)
+}
+
+// Takes the first free seat, returning it along with the secret token
+// that identifies the seat in later requests.
+func (g *Game) Join(name string) (int, string, error) {
+	return g.JoinAs(name, "")
+}
+
+// Joins like Join on behalf of an account, so the game counts towards it.
+func (g *Game) JoinAs(name, account string) (int, string, error) {
+	return g.For("").JoinAs(name, account)
+}
+
+// See Game.JoinAs.
+func (a Actor) JoinAs(name, account string) (int, string, error) {
+	g := a.game
+	g.lock(a.request)
+	defer g.unlock()
+
+	if g.suspended {
+		return 0, "", ErrSuspended
+	}
+
+	for seat := range g.players {
+		if g.players[seat] != nil {
+			continue
+		}
+
+		token, err := NewID()
+		if err != nil {
+			return 0, "", err
+		}
+
+		g.players[seat] = player.NewPlayer(name)
+		g.tokens[seat] = hashToken(token)
+		g.accounts[seat] = account
+		g.publish(Event{Type: JOINED, Seat: seat})
+
+		// Whoever was waiting may have left before anyone joined
+		for other := range g.players {
+			if other != seat && g.players[other] != nil {
+				g.leave(other)
+			}
+		}
+
+		return seat, token, nil
+	}
+
+	return 0, "", ErrGameFull
+}
+
+// Finds the seat that owns token.
+func (g *Game) Seat(token string) (int, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if token == "" {
+		return 0, false
+	}
+
+	hash := hashToken(token)
+	for seat := range g.tokens {
+		if subtle.ConstantTimeCompare([]byte(g.tokens[seat]), []byte(hash)) == 1 {
+			return seat, true
+		}
+	}
+
+	return 0, false
+}
+
+// Replaces the seat's fleet with a randomly placed one.
+func (g *Game) RandomizeFleet(seat int) error {
+	return g.For("").RandomizeFleet(seat)
+}
+
+// See Game.RandomizeFleet.
+func (a Actor) RandomizeFleet(seat int) error {
+	g := a.game
+	g.lock(a.request)
+	defer g.unlock()
+
+	p, err := g.placing(seat)
+	if err != nil {
+		return err
+	}
+
+	p.PlayerBoard = board.NewBoard()
+	if err := p.RandomizePlacement(); err != nil {
+		return err
+	}
+
+	g.placed[seat] = true
+	g.publish(Event{Type: PLACED, Seat: seat})
+	return nil
+}
+
+// Replaces the seat's fleet with ships, which must match FLEET.
+func (g *Game) PlaceFleet(seat int, ships []Ship) error {
+	return g.For("").PlaceFleet(seat, ships)
+}
+
+// See Game.PlaceFleet.
+func (a Actor) PlaceFleet(seat int, ships []Ship) error {
+	g := a.game
+	g.lock(a.request)
+	defer g.unlock()
+
+	p, err := g.placing(seat)
+	if err != nil {
+		return err
+	}
+
+	sizes := make(map[int]int)
+	for _, size := range FLEET {
+		sizes[size]++
+	}
+
+	placed := player.NewPlayer(p.Name)
+	for _, ship := range ships {
+		if sizes[ship.Size] == 0 {
+			return fmt.Errorf("%w: unexpected ship of size %d", ErrInvalidFleet, ship.Size)
+		}
+		sizes[ship.Size]--
+
+		if ship.Orientation != cell.HORIZONTAL && ship.Orientation != cell.VERTICAL {
+			return fmt.Errorf("%w: unknown orientation %q", ErrInvalidFleet, ship.Orientation)
+		}
+
+		if _, err := cell.NewCoordinate(ship.Coordinate[0], ship.Coordinate[1]); err != nil {
+			return err
+		}
+
+		if err := placed.PlaceShip(ship.Size, ship.Orientation, ship.Coordinate); err != nil {
+			return err
+		}
+	}
+
+	for size, missing := range sizes {
+		if missing > 0 {
+			return fmt.Errorf("%w: missing ship of size %d", ErrInvalidFleet, size)
+		}
+	}
+
+	p.PlayerBoard = placed.PlayerBoard
+	g.placed[seat] = true
+	g.publish(Event{Type: PLACED, Seat: seat})
+	return nil
+}
+
+// Locks in the seat's fleet. The game starts once both seats are ready.
+func (g *Game) Ready(seat int) error {
+	return g.For("").Ready(seat)
+}
+
+// See Game.Ready.
+func (a Actor) Ready(seat int) error {
+	g := a.game
+	g.lock(a.request)
+	defer g.unlock()
+
+	if _, err := g.placing(seat); err != nil {
+		return err
+	}
+
+	if !g.placed[seat] {
+		return ErrFleetNotReady
+	}
+
+	g.ready[seat] = true
+	g.publish(Event{Type: READY, Seat: seat})
+
+	if g.ready[0] && g.ready[1] {
+		g.phase = PLAYING
+		g.clocks = [SEATS]time.Duration{g.TimeControl.Clock, g.TimeControl.Clock}
+		g.publish(Event{Type: STARTED, Seat: g.turn})
+		g.startTurn()
+	}
+
+	return nil
+}
+
+// Fires at coord on behalf of seat.
+func (g *Game) Fire(seat int, coord cell.Coordinate) (Move, error) {
+	return g.For("").Fire(seat, coord)
+}
+
+// See Game.Fire.
+func (a Actor) Fire(seat int, coord cell.Coordinate) (Move, error) {
+	g := a.game
+	g.lock(a.request)
+	defer g.unlock()
+
+	if seat < 0 || seat >= SEATS {
+		return Move{}, ErrUnknownSeat
+	}
+
+	if _, err := cell.NewCoordinate(coord[0], coord[1]); err != nil {
+		return Move{}, err
+	}
+
+	if g.suspended {
+		return Move{}, ErrSuspended
+	}
+
+	if g.phase != PLAYING {
+		return Move{}, ErrWrongPhase
+	}
+
+	if g.turn != seat {
+		return Move{}, ErrNotYourTurn
+	}
+
+	for _, move := range g.moves {
+		if move.Seat == seat && move.Coordinate == coord {
+			return Move{}, ErrAlreadyFired
+		}
+	}
+
+	return g.fire(seat, coord), nil
+}
+
+// Fires at coord, which must be valid for seat to fire at, and hands
+// the turn over. Callers must hold g.mu.
+func (g *Game) fire(seat int, coord cell.Coordinate) Move {
+	g.stopTurn()
+	if g.TimeControl.Clock > 0 {
+		g.clocks[seat] += g.TimeControl.Increment
+	}
+
+	turn_player := g.players[seat]
+	enemy_player := g.players[1-seat]
+
+	hit := enemy_player.CheckHit(coord)
+	turn_player.MarkTargetAttempt(coord, hit)
+	enemy_player.MarkPlayerAttempt(coord, hit)
+
+	move := Move{Seat: seat, Coordinate: coord, Hit: hit, At: time.Now()}
+	g.moves = append(g.moves, move)
+	g.publish(Event{Type: FIRED, Seat: seat, Move: &move, At: move.At})
+
+	if board.CheckWinner(turn_player.TargetBoard, enemy_player.PlayerBoard) {
+		g.end(seat, false)
+		return move
+	}
+
+	g.turn = 1 - seat
+	g.startTurn()
+	return move
+}
+
+// Concedes the game to the other seat.
+func (g *Game) Resign(seat int) error {
+	return g.For("").Resign(seat)
+}
+
+// See Game.Resign.
+func (a Actor) Resign(seat int) error {
+	g := a.game
+	g.lock(a.request)
+	defer g.unlock()
+
+	if seat < 0 || seat >= SEATS || g.players[seat] == nil {
+		return ErrUnknownSeat
+	}
+
+	if g.suspended {
+		return ErrSuspended
+	}
+
+	if g.phase == FINISHED || g.players[1-seat] == nil {
+		return ErrWrongPhase
+	}
+
+	g.end(1-seat, true)
+	return nil
+}
+
+// Freezes the game ahead of a shutdown so it can be saved and carried on
+// by the next server: every action fails with ErrSuspended and no seat
+// forfeits or runs out of time from here on. Time already spent on the
+// current turn comes off the seat's clock. Does nothing once the game
+// has finished.
+func (g *Game) Suspend() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.suspended || g.phase == FINISHED {
+		return
+	}
+
+	g.suspended = true
+	g.stopTurn()
+	for seat, timer := range g.forfeits {
+		if timer != nil {
+			timer.Stop()
+			g.forfeits[seat] = nil
+		}
+	}
+	g.publish(Event{Type: SUSPENDED})
+}
+
+// Callers must hold g.mu.
+func (g *Game) end(winner int, forfeited bool) {
+	g.stopTurn()
+	g.phase = FINISHED
+	g.winner = winner
+	g.forfeited = forfeited
+	g.finished = time.Now()
+	g.publish(Event{Type: ENDED, Seat: winner})
+}
+
+// Returns the player in seat if the game is still being set up.
+// Callers must hold g.mu.
+func (g *Game) placing(seat int) (*player.Player, error) {
+	if seat < 0 || seat >= SEATS || g.players[seat] == nil {
+		return nil, ErrUnknownSeat
+	}
+
+	if g.suspended {
+		return nil, ErrSuspended
+	}
+
+	if g.phase != PLACING {
+		return nil, ErrWrongPhase
+	}
+
+	if g.ready[seat] {
+		return nil, ErrAlreadyReady
+	}
+
+	return g.players[seat], nil
+}
+
+// Callers must hold g.mu.
+func (g *Game) publish(event Event) {
+	event.Game = g.ID
+	event.Request = g.request
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+	if g.OnEvent != nil {
+		g.OnEvent(event)
+	}
+	g.broker.Publish(event)
+}
+
+// Seat tokens are only kept hashed so stored games can't be used to
+// take over a seat.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Returns a random hex identifier suitable for game ids and seat tokens.
+func NewID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,580 @@
+package game
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alfiehiscox/submarines/pkg/cell"
+	"github.com/alfiehiscox/submarines/pkg/chat"
+)
+
+// Returns a game where both seats have joined and placed a random fleet.
+func startedGame(t *testing.T) *Game {
+	t.Helper()
+	return timedGame(t, TimeControl{})
+}
+
+// Starts a game like startedGame under the given time control.
+func timedGame(t *testing.T, tc TimeControl) *Game {
+	t.Helper()
+
+	g := New("test_game")
+	g.TimeControl = tc
+	for seat := 0; seat < SEATS; seat++ {
+		if _, _, err := g.Join("test_player"); err != nil {
+			t.Fatalf("failed in set up: %s", err)
+		}
+		if err := g.RandomizeFleet(seat); err != nil {
+			t.Fatalf("failed in set up: %s", err)
+		}
+		if err := g.Ready(seat); err != nil {
+			t.Fatalf("failed in set up: %s", err)
+		}
+	}
+
+	return g
+}
+
+func TestJoin(t *testing.T) {
+	g := New("test_game")
+
+	seat, token, err := g.Join("test_player")
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	if found, ok := g.Seat(token); !ok || found != seat {
+		t.Fatalf("expected token to map to seat %d, got %d", seat, found)
+	}
+
+	if _, _, err := g.Join("test_player"); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	if _, _, err := g.Join("test_player"); !errors.Is(err, ErrGameFull) {
+		t.Fatalf("expected ErrGameFull, got %v", err)
+	}
+
+	if _, ok := g.Seat(""); ok {
+		t.Fatal("empty token should not map to a seat")
+	}
+}
+
+func TestReadyRequiresFleet(t *testing.T) {
+	g := New("test_game")
+	g.Join("test_player")
+
+	if err := g.Ready(0); !errors.Is(err, ErrFleetNotReady) {
+		t.Fatalf("expected ErrFleetNotReady, got %v", err)
+	}
+}
+
+func TestFire(t *testing.T) {
+	g := startedGame(t)
+
+	if _, err := g.Fire(1, cell.Coordinate{0, 0}); !errors.Is(err, ErrNotYourTurn) {
+		t.Fatalf("expected ErrNotYourTurn, got %v", err)
+	}
+
+	if _, err := g.Fire(0, cell.Coordinate{cell.BOARD_WIDTH, 0}); err == nil {
+		t.Fatal("expected error for coordinate off the board")
+	}
+
+	move, err := g.Fire(0, cell.Coordinate{0, 0})
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	if move.Hit != g.Fleet(1)[0].Occupied {
+		t.Fatalf("move hit %t does not match fleet", move.Hit)
+	}
+
+	if _, err := g.Fire(1, cell.Coordinate{0, 0}); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	if _, err := g.Fire(0, cell.Coordinate{0, 0}); !errors.Is(err, ErrAlreadyFired) {
+		t.Fatalf("expected ErrAlreadyFired, got %v", err)
+	}
+}
+
+func TestPlayToFinish(t *testing.T) {
+	g := startedGame(t)
+
+	for i := 0; i < cell.BOARD_WIDTH*cell.BOARD_HEIGHT; i++ {
+		coord := cell.Coordinate{i % cell.BOARD_WIDTH, i / cell.BOARD_WIDTH}
+		for seat := 0; seat < SEATS; seat++ {
+			if g.Snapshot().Phase == FINISHED {
+				break
+			}
+			if _, err := g.Fire(seat, coord); err != nil {
+				t.Fatalf("err should be nil: %s", err)
+			}
+		}
+	}
+
+	s := g.Snapshot()
+	if s.Phase != FINISHED || s.Winner == NO_WINNER {
+		t.Fatalf("expected a winner, got phase %s winner %d", s.Phase, s.Winner)
+	}
+
+	fleet := g.Fleet(1 - s.Winner)
+	shots := s.Shots(1 - s.Winner)
+	for i := range fleet {
+		if fleet[i].Occupied && shots[i] != cell.HIT {
+			t.Fatalf("winner has not sunk the whole fleet, cell %d still afloat", i)
+		}
+	}
+
+	if _, err := g.Fire(s.Turn, cell.Coordinate{9, 9}); !errors.Is(err, ErrWrongPhase) {
+		t.Fatalf("expected ErrWrongPhase, got %v", err)
+	}
+}
+
+func TestSnapshotAt(t *testing.T) {
+	g := startedGame(t)
+	before := time.Now()
+	time.Sleep(time.Millisecond)
+
+	if _, err := g.Fire(0, cell.Coordinate{0, 0}); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	s := g.SnapshotAt(before)
+	if len(s.Moves) != 0 || s.Turn != 0 {
+		t.Fatalf("expected no moves and seat 0 to fire, got %d moves turn %d", len(s.Moves), s.Turn)
+	}
+
+	s = g.SnapshotAt(time.Now())
+	if len(s.Moves) != 1 || s.Turn != 1 {
+		t.Fatalf("expected 1 move and seat 1 to fire, got %d moves turn %d", len(s.Moves), s.Turn)
+	}
+
+	if shots := s.Shots(1); shots[0] == cell.UNKNOWN {
+		t.Fatal("expected shot at seat 1's fleet to be known")
+	}
+}
+
+func TestActorTagsEvents(t *testing.T) {
+	g := startedGame(t)
+	events, cancel := g.Subscribe(4)
+	defer cancel()
+
+	if _, err := g.For("request_1").Fire(0, cell.Coordinate{0, 0}); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if event := <-events; event.Type != FIRED || event.Request != "request_1" {
+		t.Fatalf("expected FIRED for request_1, got %s for %q", event.Type, event.Request)
+	}
+
+	// Only for the action taken on the request's behalf
+	if _, err := g.Fire(1, cell.Coordinate{0, 0}); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if event := <-events; event.Request != "" {
+		t.Fatalf("expected no request, got %q", event.Request)
+	}
+}
+
+func TestBrokerDropsSlowSubscribers(t *testing.T) {
+	b := NewBroker()
+	slow, _ := b.Subscribe(1)
+	fast, cancel := b.Subscribe(4)
+	defer cancel()
+
+	for i := 0; i < 3; i++ {
+		b.Publish(Event{Type: FIRED})
+	}
+
+	if b.Len() != 1 {
+		t.Fatalf("expected 1 subscriber, got %d", b.Len())
+	}
+
+	count := 0
+	for range slow {
+		count++
+	}
+	if count != 1 {
+		t.Fatalf("expected slow subscriber to receive 1 event, got %d", count)
+	}
+
+	if len(fast) != 3 {
+		t.Fatalf("expected fast subscriber to receive 3 events, got %d", len(fast))
+	}
+}
+
+func TestDelay(t *testing.T) {
+	in := make(chan Event, 1)
+	done := make(chan struct{})
+	defer close(done)
+
+	out := Delay(in, 20*time.Millisecond, done)
+	in <- Event{Type: FIRED, At: time.Now()}
+
+	select {
+	case <-out:
+		t.Fatal("event should have been delayed")
+	case <-time.After(5 * time.Millisecond):
+	}
+
+	select {
+	case <-out:
+	case <-time.After(time.Second):
+		t.Fatal("event was never delivered")
+	}
+}
+
+func TestDelayOutlastsBuffer(t *testing.T) {
+	b := NewBroker()
+	events, cancel := b.Subscribe(1)
+	defer cancel()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	out := Delay(events, 50*time.Millisecond, done)
+	for i := 0; i < 10; i++ {
+		b.Publish(Event{Type: CHAT, Seat: i, At: time.Now()})
+		time.Sleep(time.Millisecond)
+	}
+
+	if b.Len() != 1 {
+		t.Fatal("expected the delayed subscriber to outlast its buffer")
+	}
+
+	for i := 0; i < 10; i++ {
+		select {
+		case event := <-out:
+			if event.Seat != i {
+				t.Fatalf("expected event %d, got %d", i, event.Seat)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d was never delivered", i)
+		}
+	}
+}
+
+func TestForfeitAfterDisconnect(t *testing.T) {
+	g := startedGame(t)
+	g.ForfeitTimeout = 10 * time.Millisecond
+
+	disconnect := g.Connect(1)
+	disconnect()
+
+	if s := g.Snapshot(); s.Away[1].IsZero() || s.ForfeitAt[1].IsZero() {
+		t.Fatal("expected seat 1 to be away")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	s := g.Snapshot()
+	if s.Phase != FINISHED || s.Winner != 0 || !s.Forfeited {
+		t.Fatalf("expected seat 0 to win by forfeit, got phase %s winner %d", s.Phase, s.Winner)
+	}
+}
+
+func TestReconnectCancelsForfeit(t *testing.T) {
+	g := startedGame(t)
+	g.ForfeitTimeout = 10 * time.Millisecond
+
+	disconnect := g.Connect(1)
+	disconnect()
+	defer g.Connect(1)()
+
+	time.Sleep(50 * time.Millisecond)
+
+	s := g.Snapshot()
+	if s.Phase != PLAYING || !s.Away[1].IsZero() {
+		t.Fatalf("expected game to carry on, got phase %s", s.Phase)
+	}
+}
+
+func TestSuspend(t *testing.T) {
+	g := startedGame(t)
+	g.ForfeitTimeout = 10 * time.Millisecond
+
+	disconnect := g.Connect(1)
+	disconnect()
+
+	g.Suspend()
+	time.Sleep(50 * time.Millisecond)
+
+	s := g.Snapshot()
+	if s.Phase != PLAYING || !s.Suspended {
+		t.Fatalf("expected a suspended game still playing, got phase %s", s.Phase)
+	}
+
+	if _, err := g.Fire(0, cell.Coordinate{0, 0}); !errors.Is(err, ErrSuspended) {
+		t.Fatalf("expected ErrSuspended, got %v", err)
+	}
+
+	if err := g.Resign(0); !errors.Is(err, ErrSuspended) {
+		t.Fatalf("expected ErrSuspended, got %v", err)
+	}
+
+	restored := Restore(g.Record())
+	if _, err := restored.Fire(0, cell.Coordinate{0, 0}); err != nil {
+		t.Fatalf("expected the restored game to carry on, got %v", err)
+	}
+}
+
+func TestMoveTimeRandomShot(t *testing.T) {
+	g := timedGame(t, TimeControl{MoveTime: 10 * time.Millisecond, OnTimeout: RANDOM_SHOT})
+
+	if s := g.Snapshot(); s.Deadline.IsZero() {
+		t.Fatal("expected the first turn to have a deadline")
+	}
+
+	time.Sleep(15 * time.Millisecond)
+
+	s := g.Snapshot()
+	if len(s.Moves) == 0 || s.Moves[0].Seat != 0 {
+		t.Fatal("expected a shot to be fired for seat 0")
+	}
+
+	if s.Phase != PLAYING {
+		t.Fatalf("expected the game to carry on, got phase %s", s.Phase)
+	}
+}
+
+func TestMoveTimeForfeit(t *testing.T) {
+	g := timedGame(t, TimeControl{MoveTime: 10 * time.Millisecond, OnTimeout: FORFEIT})
+
+	if _, err := g.Fire(0, cell.Coordinate{0, 0}); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	s := g.Snapshot()
+	if s.Phase != FINISHED || s.Winner != 0 || !s.Forfeited {
+		t.Fatalf("expected seat 0 to win on time, got phase %s winner %d", s.Phase, s.Winner)
+	}
+}
+
+func TestClock(t *testing.T) {
+	g := timedGame(t, TimeControl{Clock: 30 * time.Millisecond, Increment: time.Second, OnTimeout: RANDOM_SHOT})
+
+	if _, err := g.Fire(0, cell.Coordinate{0, 0}); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	if s := g.Snapshot(); s.Clocks[0] < time.Second {
+		t.Fatalf("expected the increment to be added, got %s", s.Clocks[0])
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	// Running out of clock loses even when timeouts fire at random
+	s := g.Snapshot()
+	if s.Phase != FINISHED || s.Winner != 0 || s.Clocks[1] != 0 {
+		t.Fatalf("expected seat 1 to lose on time, got phase %s winner %d", s.Phase, s.Winner)
+	}
+}
+
+func TestSuspendStopsClock(t *testing.T) {
+	g := timedGame(t, TimeControl{Clock: 20 * time.Millisecond})
+
+	g.Suspend()
+	time.Sleep(50 * time.Millisecond)
+
+	if s := g.Snapshot(); s.Phase != PLAYING || !s.Deadline.IsZero() {
+		t.Fatalf("expected a suspended game not to be timed, got phase %s", s.Phase)
+	}
+
+	restored := Restore(g.Record())
+	if s := restored.Snapshot(); s.Clocks[0] == 0 || s.Deadline.IsZero() {
+		t.Fatal("expected the restored game to carry on timing the turn")
+	}
+}
+
+func TestPlaceFleet(t *testing.T) {
+	g := New("test_game")
+	g.Join("test_player")
+
+	tests := []struct {
+		ships []Ship
+		valid bool
+	}{
+		{
+			ships: []Ship{
+				{5, cell.HORIZONTAL, cell.Coordinate{0, 0}},
+				{4, cell.HORIZONTAL, cell.Coordinate{0, 1}},
+				{3, cell.HORIZONTAL, cell.Coordinate{0, 2}},
+				{3, cell.HORIZONTAL, cell.Coordinate{0, 3}},
+				{2, cell.VERTICAL, cell.Coordinate{9, 8}},
+			},
+			valid: true,
+		},
+		// missing ship
+		{ships: []Ship{{5, cell.HORIZONTAL, cell.Coordinate{0, 0}}}},
+		// overlapping ships
+		{
+			ships: []Ship{
+				{5, cell.HORIZONTAL, cell.Coordinate{0, 0}},
+				{4, cell.VERTICAL, cell.Coordinate{0, 0}},
+				{3, cell.HORIZONTAL, cell.Coordinate{0, 2}},
+				{3, cell.HORIZONTAL, cell.Coordinate{0, 3}},
+				{2, cell.VERTICAL, cell.Coordinate{9, 8}},
+			},
+		},
+		// off the board
+		{
+			ships: []Ship{
+				{5, cell.HORIZONTAL, cell.Coordinate{0, 0}},
+				{4, cell.HORIZONTAL, cell.Coordinate{0, 1}},
+				{3, cell.HORIZONTAL, cell.Coordinate{0, 2}},
+				{3, cell.HORIZONTAL, cell.Coordinate{0, 3}},
+				{2, cell.VERTICAL, cell.Coordinate{10, 8}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		err := g.PlaceFleet(0, test.ships)
+		if test.valid && err != nil {
+			t.Fatalf("err should be nil: %s", err)
+		}
+		if !test.valid && err == nil {
+			t.Fatalf("expected error, got nil: %v", test.ships)
+		}
+	}
+}
+
+func TestMatchmaker(t *testing.T) {
+	m := NewMatchmaker(NewRegistry())
+
+	first, err := m.Enqueue("first")
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	if m.Len() != 1 {
+		t.Fatalf("expected 1 queued, got %d", m.Len())
+	}
+
+	second, err := m.Enqueue("second")
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	a, b := <-first.Matched(), <-second.Matched()
+	if a.Game != b.Game || a.Seat == b.Seat {
+		t.Fatal("expected both tickets in different seats of the same game")
+	}
+
+	if m.Len() != 0 {
+		t.Fatalf("expected empty queue, got %d", m.Len())
+	}
+
+	cancelled, _ := m.Enqueue("cancelled")
+	m.Cancel(cancelled)
+	if m.Len() != 0 {
+		t.Fatalf("expected empty queue, got %d", m.Len())
+	}
+}
+
+func TestMatchmakerSameAccount(t *testing.T) {
+	m := NewMatchmaker(NewRegistry())
+
+	first, _ := m.EnqueueAs("alice", "a1")
+	if _, err := m.EnqueueAs("alice", "a1"); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	if m.Len() != 2 {
+		t.Fatalf("expected an account not to be matched with itself, got %d queued", m.Len())
+	}
+
+	other, err := m.EnqueueAs("bob", "b1")
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	a, b := <-first.Matched(), <-other.Matched()
+	if a.Game != b.Game {
+		t.Fatal("expected the longest waiting ticket to be matched")
+	}
+
+	if m.Len() != 1 {
+		t.Fatalf("expected the second tab to keep waiting, got %d queued", m.Len())
+	}
+}
+
+func TestBoards(t *testing.T) {
+	g := startedGame(t)
+	g.Fire(0, cell.Coordinate{0, 0})
+	g.Fire(1, cell.Coordinate{1, 0})
+	r := g.Record()
+
+	before := r.Boards(0)
+	after := r.Boards(1)
+
+	fleet := g.Fleet(1)
+	want := cell.MISS
+	if fleet[0].Occupied {
+		want = cell.HIT
+	}
+	if after[1][0] != want {
+		t.Fatalf("expected the first shot to show as %v, got %v", want, after[1][0])
+	}
+
+	for i := range fleet {
+		if fleet[i].Occupied && before[1][i] != cell.SHIP {
+			t.Fatalf("expected cell %d to show a ship before any shots", i)
+		}
+	}
+
+	if after[0][1] != before[0][1] {
+		t.Fatal("expected the second shot not to be shown after one move")
+	}
+}
+
+func TestChat(t *testing.T) {
+	g := startedGame(t)
+	g.ChatFilter = chat.NewFilter([]string{"darn"})
+
+	message, err := g.Say(0, "  darn, a miss  ")
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	if message.Text != "****, a miss" {
+		t.Fatalf("expected a trimmed and filtered message, got %q", message.Text)
+	}
+
+	if _, err := g.Say(0, strings.Repeat("a", MAX_MESSAGE+1)); !errors.Is(err, ErrMessageTooLong) {
+		t.Fatalf("expected ErrMessageTooLong, got %v", err)
+	}
+
+	if _, err := g.Say(0, " "); !errors.Is(err, ErrEmptyMessage) {
+		t.Fatalf("expected ErrEmptyMessage, got %v", err)
+	}
+
+	for i := 1; i < CHAT_BURST; i++ {
+		if _, err := g.Say(0, "hello"); err != nil {
+			t.Fatalf("err should be nil: %s", err)
+		}
+	}
+	if _, err := g.Say(0, "hello"); !errors.Is(err, ErrChatTooFast) {
+		t.Fatalf("expected ErrChatTooFast, got %v", err)
+	}
+
+	// Limited per seat
+	if _, err := g.Say(1, "hello"); err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	g.Mute(1, true)
+	g.Report(1)
+	g.Report(1)
+	if s := g.Snapshot(); !s.Muted[1] || !s.Reported[1] || len(g.Record().Reports) != 1 {
+		t.Fatal("expected seat 1 to have muted and reported once")
+	}
+
+	g.Resign(0)
+	if _, err := g.Say(1, "gg"); !errors.Is(err, ErrChatClosed) {
+		t.Fatalf("expected ErrChatClosed, got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package game
+
+import (
+	"sync"
+)
+
+// The seat a queued player was given once matched.
+type Match struct {
+	Game  *Game
+	Seat  int
+	Token string
+}
+
+// A place in the matchmaking queue.
+type Ticket struct {
+	Name    string
+	Account string
+	matched chan Match
+}
+
+// Matched receives exactly once, when the ticket is paired with another.
+func (t *Ticket) Matched() <-chan Match {
+	return t.matched
+}
+
+// Matchmaker pairs queued players, first come first served, and starts
+// a rated game in the registry for each pair. Humans and bots share a
+// queue, and so a rating scale.
+type Matchmaker struct {
+	games *Registry
+
+	mu    sync.Mutex
+	queue []*Ticket
+}
+
+func NewMatchmaker(games *Registry) *Matchmaker {
+	return &Matchmaker{games: games}
+}
+
+// Queues name for a game. If someone is already waiting the two are
+// matched straight away.
+func (m *Matchmaker) Enqueue(name string) (*Ticket, error) {
+	return m.EnqueueAs(name, "")
+}
+
+// Queues like Enqueue on behalf of an account. An account is never
+// matched against itself, so a second tab waits for someone else.
+func (m *Matchmaker) EnqueueAs(name, account string) (*Ticket, error) {
+	ticket := &Ticket{Name: name, Account: account, matched: make(chan Match, 1)}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.opponent(account)
+	if i < 0 {
+		m.queue = append(m.queue, ticket)
+		return ticket, nil
+	}
+
+	waiting := m.queue[i]
+
+	g, err := m.games.CreateRated()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range []*Ticket{waiting, ticket} {
+		seat, token, err := g.JoinAs(t.Name, t.Account)
+		if err != nil {
+			return nil, err
+		}
+		t.matched <- Match{Game: g, Seat: seat, Token: token}
+	}
+
+	m.queue = append(m.queue[:i], m.queue[i+1:]...)
+	return ticket, nil
+}
+
+// Returns the index of the longest waiting ticket account may be
+// matched with, or -1 if there is none.
+func (m *Matchmaker) opponent(account string) int {
+	for i, t := range m.queue {
+		if account == "" || t.Account != account {
+			return i
+		}
+	}
+	return -1
+}
+
+// Leaves the queue. Does nothing if the ticket has already been matched.
+func (m *Matchmaker) Cancel(ticket *Ticket) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, t := range m.queue {
+		if t == ticket {
+			m.queue = append(m.queue[:i], m.queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// Returns the number of players waiting for a match.
+func (m *Matchmaker) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.queue)
+}
@@ -0,0 +1,77 @@
+package game
+
+import (
+	"time"
+)
+
+// Registers a live connection from seat, such as an open event stream,
+// returning a function to call when it closes. A seat whose last
+// connection closes mid-game has ForfeitTimeout to come back before the
+// game is awarded to the opponent.
+func (g *Game) Connect(seat int) func() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if seat < 0 || seat >= SEATS {
+		return func() {}
+	}
+
+	g.seen[seat] = true
+	g.connections[seat]++
+	if g.connections[seat] == 1 && !g.away[seat].IsZero() {
+		g.away[seat] = time.Time{}
+		if g.forfeits[seat] != nil {
+			g.forfeits[seat].Stop()
+			g.forfeits[seat] = nil
+		}
+		g.publish(Event{Type: RECONNECTED, Seat: seat})
+	}
+
+	var once bool
+	return func() {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		if once {
+			return
+		}
+		once = true
+
+		g.connections[seat]--
+		g.leave(seat)
+	}
+}
+
+// Starts the forfeit clock for a seat with no connections, once there
+// is an opponent to award the game to. Seats that have never connected,
+// such as API clients, are not tracked. Callers must hold g.mu.
+func (g *Game) leave(seat int) {
+	if !g.seen[seat] || g.connections[seat] > 0 {
+		return
+	}
+
+	if g.phase == FINISHED || g.suspended || g.players[1-seat] == nil || !g.away[seat].IsZero() {
+		return
+	}
+
+	g.away[seat] = time.Now()
+	if g.ForfeitTimeout > 0 {
+		g.forfeits[seat] = time.AfterFunc(g.ForfeitTimeout, func() {
+			g.forfeit(seat)
+		})
+	}
+	g.publish(Event{Type: DISCONNECTED, Seat: seat})
+}
+
+// Ends the game in favour of the other seat if seat is still away.
+func (g *Game) forfeit(seat int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.connections[seat] > 0 || g.away[seat].IsZero() || g.phase == FINISHED || g.suspended {
+		return
+	}
+
+	g.forfeits[seat] = nil
+	g.end(1-seat, true)
+}
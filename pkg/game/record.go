@@ -0,0 +1,156 @@
+package game
+
+import (
+	"time"
+
+	"github.com/alfiehiscox/submarines/pkg/board"
+	"github.com/alfiehiscox/submarines/pkg/cell"
+	"github.com/alfiehiscox/submarines/pkg/player"
+)
+
+// Record is everything needed to store a game and bring it back with
+// Restore. Unlike Snapshot it includes both fleets, so it must never be
+// shown to players.
+type Record struct {
+	ID        string
+	Created   time.Time
+	Finished  time.Time
+	Phase     Phase
+	Turn      int
+	Winner    int
+	Forfeited bool
+	Rated     bool
+	Seats     [SEATS]SeatRecord
+	Moves     []Move
+
+	// Time left on each seat's clock when the record was taken
+	TimeControl TimeControl
+	Clocks      [SEATS]time.Duration
+
+	Chat    []Message
+	Reports []Report
+}
+
+type SeatRecord struct {
+	Joined    bool
+	Name      string
+	Account   string
+	TokenHash string
+	Placed    bool
+	Ready     bool
+
+	// Whether the seat has muted its opponent's messages
+	Muted bool
+
+	// Cells covered by the seat's ships
+	Fleet []cell.Coordinate
+}
+
+func (g *Game) Record() Record {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	r := Record{
+		ID:        g.ID,
+		Created:   g.Created,
+		Finished:  g.finished,
+		Phase:     g.phase,
+		Turn:      g.turn,
+		Winner:    g.winner,
+		Forfeited: g.forfeited,
+		Rated:     g.Rated,
+		Moves:     make([]Move, len(g.moves)),
+
+		TimeControl: g.TimeControl,
+		Clocks:      g.remaining(),
+
+		Chat:    make([]Message, len(g.chat)),
+		Reports: make([]Report, len(g.reports)),
+	}
+	copy(r.Moves, g.moves)
+	copy(r.Chat, g.chat)
+	copy(r.Reports, g.reports)
+
+	for seat, p := range g.players {
+		if p == nil {
+			continue
+		}
+
+		r.Seats[seat] = SeatRecord{
+			Joined:    true,
+			Name:      p.Name,
+			Account:   g.accounts[seat],
+			TokenHash: g.tokens[seat],
+			Placed:    g.placed[seat],
+			Ready:     g.ready[seat],
+			Muted:     g.muted[seat],
+		}
+
+		for i, c := range p.PlayerBoard {
+			if c.Occupied {
+				coord := cell.Coordinate{i % cell.BOARD_WIDTH, i / cell.BOARD_WIDTH}
+				r.Seats[seat].Fleet = append(r.Seats[seat].Fleet, coord)
+			}
+		}
+	}
+
+	return r
+}
+
+// Rebuilds a game from its record. Nobody is connected to a restored
+// game, so no forfeit clocks run until its seats connect and leave again.
+// The turn in progress is timed afresh from when it is restored.
+func Restore(r Record) *Game {
+	g := New(r.ID)
+	g.Created = r.Created
+	g.finished = r.Finished
+	g.phase = r.Phase
+	g.turn = r.Turn
+	g.winner = r.Winner
+	g.forfeited = r.Forfeited
+	g.Rated = r.Rated
+	g.TimeControl = r.TimeControl
+	g.clocks = r.Clocks
+	g.chat = append([]Message(nil), r.Chat...)
+	g.reports = append([]Report(nil), r.Reports...)
+	g.moves = make([]Move, len(r.Moves))
+	copy(g.moves, r.Moves)
+
+	for seat, s := range r.Seats {
+		if !s.Joined {
+			continue
+		}
+
+		p := player.NewPlayer(s.Name)
+		p.PlayerBoard = fleetBoard(s.Fleet)
+
+		g.players[seat] = p
+		g.tokens[seat] = s.TokenHash
+		g.accounts[seat] = s.Account
+		g.placed[seat] = s.Placed
+		g.ready[seat] = s.Ready
+		g.muted[seat] = s.Muted
+	}
+
+	// Shots are only kept as moves, so mark them on the boards again
+	for _, move := range g.moves {
+		turn_player, enemy_player := g.players[move.Seat], g.players[1-move.Seat]
+		if turn_player == nil || enemy_player == nil {
+			continue
+		}
+		turn_player.MarkTargetAttempt(move.Coordinate, move.Hit)
+		enemy_player.MarkPlayerAttempt(move.Coordinate, move.Hit)
+	}
+
+	g.startTurn()
+	return g
+}
+
+// Returns a board with only the given cells occupied.
+func fleetBoard(cells []cell.Coordinate) board.Board {
+	b := board.NewBoard()
+	for _, coord := range cells {
+		b[coord.ToIndex()].Occupied = true
+	}
+	return b
+}
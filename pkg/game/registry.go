@@ -0,0 +1,144 @@
+package game
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alfiehiscox/submarines/pkg/chat"
+)
+
+// Registry holds the games currently known to the server.
+type Registry struct {
+	// Applied to every game created
+	ForfeitTimeout time.Duration
+
+	// Applied to every game created or added
+	ChatFilter *chat.Filter
+
+	// Applied to games created from now on. Restored games keep their
+	// own.
+	TimeControl TimeControl
+
+	// Called with every game created or added, for example to start
+	// saving it
+	OnAdd func(g *Game)
+
+	// Given to every game created or added, see Game.OnEvent
+	OnEvent func(Event)
+
+	mu        sync.RWMutex
+	games     map[string]*Game
+	suspended bool
+}
+
+func NewRegistry() *Registry {
+	return &Registry{games: make(map[string]*Game)}
+}
+
+// Creates and registers a new game.
+func (r *Registry) Create() (*Game, error) {
+	return r.create(false)
+}
+
+// Creates and registers a new rated game.
+func (r *Registry) CreateRated() (*Game, error) {
+	return r.create(true)
+}
+
+func (r *Registry) create(rated bool) (*Game, error) {
+	r.mu.RLock()
+	suspended := r.suspended
+	r.mu.RUnlock()
+
+	if suspended {
+		return nil, ErrSuspended
+	}
+
+	id, err := NewID()
+	if err != nil {
+		return nil, err
+	}
+
+	g := New(id)
+	g.Rated = rated
+	g.TimeControl = r.TimeControl
+	r.Add(g)
+
+	return g, nil
+}
+
+// Registers an existing game, such as one restored from storage.
+func (r *Registry) Add(g *Game) {
+	g.ForfeitTimeout = r.ForfeitTimeout
+	g.ChatFilter = r.ChatFilter
+	g.OnEvent = r.OnEvent
+
+	r.mu.Lock()
+	r.games[g.ID] = g
+	suspended := r.suspended
+	r.mu.Unlock()
+
+	// Raced with Suspend
+	if suspended {
+		g.Suspend()
+	}
+
+	if r.OnAdd != nil {
+		r.OnAdd(g)
+	}
+}
+
+// Forgets a game, such as one that has finished and been saved for the
+// last time.
+func (r *Registry) Remove(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.games, id)
+}
+
+func (r *Registry) Get(id string) (*Game, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	g, ok := r.games[id]
+	return g, ok
+}
+
+// Suspends every game and stops new ones being created, ahead of a
+// shutdown.
+func (r *Registry) Suspend() {
+	r.mu.Lock()
+	r.suspended = true
+	games := make([]*Game, 0, len(r.games))
+	for _, g := range r.games {
+		games = append(games, g)
+	}
+	r.mu.Unlock()
+
+	for _, g := range games {
+		g.Suspend()
+	}
+}
+
+// Returns games that have not finished yet, oldest first.
+func (r *Registry) Live() []*Game {
+	r.mu.RLock()
+	games := make([]*Game, 0, len(r.games))
+	for _, g := range r.games {
+		games = append(games, g)
+	}
+	r.mu.RUnlock()
+
+	live := games[:0]
+	for _, g := range games {
+		if g.Snapshot().Phase != FINISHED {
+			live = append(live, g)
+		}
+	}
+
+	sort.Slice(live, func(i, j int) bool {
+		return live[i].Created.Before(live[j].Created)
+	})
+
+	return live
+}
@@ -0,0 +1,35 @@
+package game
+
+import (
+	"github.com/alfiehiscox/submarines/pkg/cell"
+)
+
+// Returns each seat's fleet as it was after the first n moves, ships and
+// all. Fleets are secret until the game is over, so only use this on
+// finished games.
+func (r Record) Boards(n int) [SEATS][]cell.State {
+	n = max(0, min(n, len(r.Moves)))
+
+	var boards [SEATS][]cell.State
+	for seat := range boards {
+		board := make([]cell.State, cell.BOARD_WIDTH*cell.BOARD_HEIGHT)
+		for i := range board {
+			board[i] = cell.UNKNOWN
+		}
+		for _, coord := range r.Seats[seat].Fleet {
+			board[coord.ToIndex()] = cell.SHIP
+		}
+		boards[seat] = board
+	}
+
+	for _, move := range r.Moves[:n] {
+		board := boards[1-move.Seat]
+		if move.Hit {
+			board[move.Coordinate.ToIndex()] = cell.HIT
+		} else {
+			board[move.Coordinate.ToIndex()] = cell.MISS
+		}
+	}
+
+	return boards
+}
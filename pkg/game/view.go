@@ -0,0 +1,170 @@
+package game
+
+import (
+	"time"
+
+	"github.com/alfiehiscox/submarines/pkg/board"
+	"github.com/alfiehiscox/submarines/pkg/cell"
+)
+
+// Snapshot is a copy of the public state of a game at a point in time.
+// It never contains ship positions, so it is safe to show to anyone.
+type Snapshot struct {
+	ID     string
+	Names  [SEATS]string
+	Joined [SEATS]bool
+	Placed [SEATS]bool
+	Ready  [SEATS]bool
+	Phase  Phase
+	Turn   int
+	Winner int
+	Moves  []Move
+
+	// Set when the game was won because the loser never came back
+	Forfeited bool
+
+	// When each seat lost its connection and when it will forfeit if
+	// it hasn't returned. Zero while the seat is connected.
+	Away      [SEATS]time.Time
+	ForfeitAt [SEATS]time.Time
+
+	// Set while the server restarts, see Game.Suspend
+	Suspended bool
+
+	// Time left on each seat's clock, and when the seat to fire runs
+	// out of time. Only set for games with a TimeControl.
+	TimeControl TimeControl
+	Clocks      [SEATS]time.Duration
+	Deadline    time.Time
+
+	// Everything said in the game's chat, and which seats have muted or
+	// reported their opponent
+	Chat     []Message
+	Muted    [SEATS]bool
+	Reported [SEATS]bool
+}
+
+func (g *Game) Snapshot() Snapshot {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.snapshot(len(g.moves))
+}
+
+// Returns the snapshot as it was at cutoff, leaving out any moves made
+// since. Used to show spectators a delayed view of the game.
+func (g *Game) SnapshotAt(cutoff time.Time) Snapshot {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	n := 0
+	for n < len(g.moves) && !g.moves[n].At.After(cutoff) {
+		n++
+	}
+
+	s := g.snapshot(n)
+
+	m := 0
+	for m < len(g.chat) && !g.chat[m].At.After(cutoff) {
+		m++
+	}
+	s.Chat = s.Chat[:m]
+
+	// Clocks are only known for now
+	if n < len(g.moves) {
+		s.Clocks = [SEATS]time.Duration{}
+		s.Deadline = time.Time{}
+	}
+
+	if s.Phase == FINISHED && g.finished.After(cutoff) {
+		s.Phase = PLAYING
+		s.Winner = NO_WINNER
+		s.Forfeited = false
+	}
+
+	// Seats alternate, starting with seat 0
+	if s.Phase == PLAYING {
+		s.Turn = n % SEATS
+	}
+
+	return s
+}
+
+// Callers must hold g.mu.
+func (g *Game) snapshot(moves int) Snapshot {
+	s := Snapshot{
+		ID:     g.ID,
+		Placed: g.placed,
+		Ready:  g.ready,
+		Phase:  g.phase,
+		Turn:   g.turn,
+		Winner: g.winner,
+		Moves:  make([]Move, moves),
+
+		Forfeited: g.forfeited,
+		Away:      g.away,
+		Suspended: g.suspended,
+
+		TimeControl: g.TimeControl,
+		Clocks:      g.remaining(),
+		Deadline:    g.deadline(),
+
+		Chat:  make([]Message, len(g.chat)),
+		Muted: g.muted,
+	}
+
+	copy(s.Chat, g.chat)
+	for _, report := range g.reports {
+		s.Reported[report.Seat] = true
+	}
+
+	for seat := range g.away {
+		if !g.away[seat].IsZero() && g.ForfeitTimeout > 0 {
+			s.ForfeitAt[seat] = g.away[seat].Add(g.ForfeitTimeout)
+		}
+	}
+
+	copy(s.Moves, g.moves)
+	for seat, p := range g.players {
+		if p != nil {
+			s.Names[seat] = p.Name
+			s.Joined[seat] = true
+		}
+	}
+
+	return s
+}
+
+// Returns a copy of the fleet in seat. This is private to that seat.
+func (g *Game) Fleet(seat int) board.Board {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	fleet := board.NewBoard()
+	if seat >= 0 && seat < SEATS && g.players[seat] != nil {
+		copy(fleet, g.players[seat].PlayerBoard)
+	}
+	return fleet
+}
+
+// Returns what is publicly known about the fleet in seat: which cells
+// the opponent has fired at and whether they hit.
+func (s Snapshot) Shots(seat int) []cell.State {
+	shots := make([]cell.State, cell.BOARD_WIDTH*cell.BOARD_HEIGHT)
+	for i := range shots {
+		shots[i] = cell.UNKNOWN
+	}
+
+	for _, move := range s.Moves {
+		if move.Seat == seat {
+			continue
+		}
+
+		if move.Hit {
+			shots[move.Coordinate.ToIndex()] = cell.HIT
+		} else {
+			shots[move.Coordinate.ToIndex()] = cell.MISS
+		}
+	}
+
+	return shots
+}
@@ -0,0 +1,78 @@
+package html
+
+import (
+	"github.com/alfiehiscox/submarines/pkg/account"
+	"github.com/alfiehiscox/submarines/pkg/i18n"
+	. "maragu.dev/gomponents"
+	htmx "maragu.dev/gomponents-htmx"
+	. "maragu.dev/gomponents/html"
+)
+
+// The register page, with problem shown if the last attempt failed.
+func Register(l i18n.Locale, problem string) Node {
+	return page(l,
+		Div(Class("flex flex-col items-center gap-4"),
+			H1(Class("text-xl"), Text(l.T("account.register"))),
+			P(Class("text-sm"), Text(l.T("account.register_note"))),
+			RegisterForm(l, problem),
+		),
+	)
+}
+
+// The register form, with problem shown if the last attempt failed.
+func RegisterForm(l i18n.Locale, problem string) Node {
+	return accountForm(l, "/register", l.T("account.register"), "new-password", problem)
+}
+
+// The login page, with problem shown if the last attempt failed.
+func Login(l i18n.Locale, problem string) Node {
+	return page(l,
+		Div(Class("flex flex-col items-center gap-4"),
+			H1(Class("text-xl"), Text(l.T("account.login"))),
+			LoginForm(l, problem),
+			A(Href("/register"), Class("hover:underline"), Text(l.T("account.no_account"))),
+		),
+	)
+}
+
+// The login form, with problem shown if the last attempt failed.
+func LoginForm(l i18n.Locale, problem string) Node {
+	return accountForm(l, "/login", l.T("account.login"), "current-password", problem)
+}
+
+// Shows who is playing, with links to log in or out.
+func accountBar(l i18n.Locale, a *account.Account) Node {
+	link := "hover:underline"
+
+	switch {
+	case a == nil:
+		return P(Class("text-sm"), sentence(l.T("account.anonymous"),
+			A(Href("/login"), Class(link), Text(l.T("account.login"))),
+			A(Href("/register"), Class(link), Text(l.T("account.register_link"))),
+		))
+	case a.Guest:
+		return P(Class("text-sm"), sentence(l.T("account.guest"),
+			Text(a.Name),
+			A(Href("/register"), Class(link), Text(l.T("account.register"))),
+			A(Href("/login"), Class(link), Text(l.T("account.login_link"))),
+		))
+	default:
+		return P(Class("text-sm"),
+			sentence(l.T("account.logged_in"), playerLink(l, *a)), Text(" "),
+			Button(Class(link), htmx.Post("/logout"), Text(l.T("account.logout"))),
+		)
+	}
+}
+
+func accountForm(l i18n.Locale, action, label, autocomplete, problem string) Node {
+	field := "rounded border px-2 py-1"
+
+	return Form(Class("flex flex-col gap-2"),
+		htmx.Post(action),
+		htmx.Swap("outerHTML"),
+		If(problem != "", P(Class("text-red-500"), Text(problem))),
+		Input(Type("text"), Name("name"), Placeholder(l.T("account.name")), AutoComplete("username"), Required(), Class(field)),
+		Input(Type("password"), Name("password"), Placeholder(l.T("account.password")), AutoComplete(autocomplete), Required(), Class(field)),
+		Button(Type("submit"), Class("rounded border px-4 py-2 hover:bg-blue-500"), Text(label)),
+	)
+}
@@ -0,0 +1,88 @@
+package html
+
+import (
+	"fmt"
+
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/alfiehiscox/submarines/pkg/i18n"
+	. "maragu.dev/gomponents"
+	htmx "maragu.dev/gomponents-htmx"
+	. "maragu.dev/gomponents/html"
+)
+
+// Chat for a seated player, with problem shown if their last message
+// was refused. The log is kept up to date over SSE, separately from the
+// form so pushes don't wipe what they are typing.
+func ChatBox(l i18n.Locale, s game.Snapshot, seat int, problem string) Node {
+	base := fmt.Sprintf("/games/%s/chat", s.ID)
+	open := s.Phase != game.FINISHED && !s.Suspended
+
+	mute, muteLabel := "true", l.T("chat.mute")
+	if s.Muted[seat] {
+		mute, muteLabel = "false", l.T("chat.unmute")
+	}
+
+	return Div(ID("chat"), Class("w-full flex flex-col gap-2"),
+		H2(Text(l.T("chat.title"))),
+		Div(Attr("sse-swap", "chat"), ChatLog(l, s, seat)),
+		If(problem != "", P(Class("text-red-500"), Text(problem))),
+		If(open,
+			Form(Class("flex gap-2"),
+				htmx.Post(base),
+				htmx.Target("#chat"),
+				htmx.Swap("outerHTML"),
+				Input(Type("text"), Name("text"), Placeholder(l.T("chat.placeholder")), MaxLength(fmt.Sprint(game.MAX_MESSAGE)),
+					AutoComplete("off"), Required(), Class("grow rounded border px-2 py-1")),
+				Button(Type("submit"), Class("rounded border px-4 py-2 hover:bg-blue-500"), Text(l.T("chat.send"))),
+			),
+		),
+		If(s.Joined[1-seat],
+			Div(Class("flex gap-2 text-sm"),
+				button(muteLabel, htmx.Post(base+"/mute?muted="+mute), htmx.Target("#chat"), htmx.Swap("outerHTML")),
+				If(!s.Reported[seat],
+					button(l.T("chat.report"), htmx.Post(base+"/report"), htmx.Target("#chat"), htmx.Swap("outerHTML")),
+				),
+				If(s.Reported[seat], Span(Class("px-4 py-2"), Text(l.T("chat.reported")))),
+			),
+		),
+	)
+}
+
+// The messages seat can see, leaving out its opponent's if muted.
+func ChatLog(l i18n.Locale, s game.Snapshot, seat int) Node {
+	messages := make([]game.Message, 0, len(s.Chat))
+	for _, message := range s.Chat {
+		if message.Seat == seat || !s.Muted[seat] {
+			messages = append(messages, message)
+		}
+	}
+
+	return Group{
+		chatLog(l, s, messages),
+		If(s.Muted[seat], P(Class("text-sm"), Text(l.T("chat.muted", name(l, s, 1-seat))))),
+	}
+}
+
+// Chat for spectators, who can read but not post.
+func SpectatorChat(l i18n.Locale, s game.Snapshot) Node {
+	return Div(Class("w-full flex flex-col gap-2"),
+		H2(Text(l.T("chat.title"))),
+		Div(Attr("sse-swap", "chat"), SpectatorChatLog(l, s)),
+	)
+}
+
+func SpectatorChatLog(l i18n.Locale, s game.Snapshot) Node {
+	return chatLog(l, s, s.Chat)
+}
+
+func chatLog(l i18n.Locale, s game.Snapshot, messages []game.Message) Node {
+	if len(messages) == 0 {
+		return P(Class("text-sm"), Text(l.T("chat.empty")))
+	}
+
+	return Ul(Class("flex flex-col gap-1 max-h-48 overflow-y-auto"),
+		Map(messages, func(m game.Message) Node {
+			return Li(Span(Class("font-bold"), Text(name(l, s, m.Seat)+": ")), Text(m.Text))
+		}),
+	)
+}
@@ -0,0 +1,29 @@
+package html
+
+import (
+	"github.com/alfiehiscox/submarines/pkg/i18n"
+	. "maragu.dev/gomponents"
+	. "maragu.dev/gomponents/html"
+)
+
+// Shown when a request can't be handled. requestID lets whoever reports
+// the problem point at the right logs, and is left out if empty.
+func ErrorPage(l i18n.Locale, title, message, requestID string) Node {
+	return page(l,
+		Div(Class("flex flex-col items-center gap-4"),
+			H1(Class("text-xl"), Text(title)),
+			P(Text(message)),
+			If(requestID != "", P(Class("text-sm"), Text(l.T("error.request_id", requestID)))),
+			A(Href("/"), Class("hover:underline"), Text(l.T("error.home"))),
+		),
+	)
+}
+
+// Shown in place of what an htmx request would have swapped in, see
+// page's #error.
+func ErrorMessage(l i18n.Locale, message, requestID string) Node {
+	return Div(Class("rounded border border-red-500 bg-white px-4 py-2"),
+		P(Class("text-red-500"), Text(message)),
+		If(requestID != "", P(Class("text-sm"), Text(l.T("error.request_id", requestID)))),
+	)
+}
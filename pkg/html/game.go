@@ -0,0 +1,362 @@
+package html
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alfiehiscox/submarines/pkg/cell"
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/alfiehiscox/submarines/pkg/i18n"
+	"github.com/alfiehiscox/submarines/pkg/view"
+	. "maragu.dev/gomponents"
+	htmx "maragu.dev/gomponents-htmx"
+	. "maragu.dev/gomponents/html"
+)
+
+// Passed to Announcement for spectators, who aren't in a seat
+const NO_SEAT = -1
+
+// Page for a seated player. The panel is kept up to date over SSE.
+// The resume link lets the player pick the game back up from another
+// browser if they lose this one.
+func Game(l i18n.Locale, v view.Player, resume string) Node {
+	return page(l,
+		Div(Class("w-2/3 flex flex-col items-center gap-4"),
+			htmx.Ext("sse"),
+			Attr("sse-connect", fmt.Sprintf("/games/%s/events", v.ID)),
+			Div(ID("game"), Class("w-full"),
+				Attr("sse-swap", "game"),
+				GamePanel(l, v),
+			),
+			liveRegion("announcement", Attr("sse-swap", "shot"), Announcement(l, v.Snapshot, v.Seat)),
+			ChatBox(l, v.Snapshot, v.Seat, ""),
+			P(Class("text-sm"), Text(l.T("game.resume")), Code(Text(resume))),
+		),
+	)
+}
+
+// Page for someone who has followed a link to a game with a free seat.
+func JoinGame(l i18n.Locale, s game.Snapshot) Node {
+	return page(l,
+		Div(Class("flex flex-col items-center gap-4"),
+			H1(Class("text-xl"), Text(l.T("game.waiting_for", s.Names[0]))),
+			button(l.T("game.join"), htmx.Post(fmt.Sprintf("/games/%s/join", s.ID))),
+		),
+	)
+}
+
+func GamePanel(l i18n.Locale, v view.Player) Node {
+	s, seat := v.Snapshot, v.Seat
+	base := fmt.Sprintf("/games/%s", s.ID)
+
+	return Div(Class("w-full flex flex-col items-center gap-4"),
+		H1(Class("text-xl"), Text(status(l, s, seat))),
+		Div(Attr("sse-swap", "clock"), Clocks(l, s)),
+		If(!s.Joined[1-seat],
+			P(Text(l.T("game.share")), Code(Text(base))),
+		),
+		If(s.Phase != game.FINISHED && !s.Away[1-seat].IsZero(),
+			P(Class("animate-pulse"), Text(away(l, s, 1-seat))),
+		),
+		If(s.Suspended,
+			P(Class("animate-pulse"), Text(l.T("game.restarting"))),
+		),
+		Div(Class("w-full flex justify-around gap-8"),
+			Div(Class("w-2/5 flex flex-col items-center gap-2"),
+				H2(Text(l.T("game.your_fleet"))),
+				board("fleet", l.T("game.your_fleet"), func(i int) Node {
+					return BoardCell(l, i, v.Fleet[i])
+				}),
+			),
+			If(s.Phase != game.PLACING,
+				Div(Class("w-2/5 flex flex-col items-center gap-2"),
+					H2(Text(l.T("game.enemy_waters"))),
+					board("waters", l.T("game.enemy_waters"), func(i int) Node {
+						if s.Phase == game.PLAYING && !s.Suspended && s.Turn == seat {
+							x, y := i%cell.BOARD_WIDTH, i/cell.BOARD_WIDTH
+							return BoardCell(l, i, v.Waters[i],
+								htmx.Post(fmt.Sprintf("%s/fire/%d/%d", base, x, y)),
+								htmx.Target("#game"),
+							)
+						}
+						return BoardCell(l, i, v.Waters[i])
+					}),
+				),
+			),
+		),
+		If(s.Phase == game.PLAYING, P(Class("text-sm"), Text(l.T("game.keyboard")))),
+		If(s.Phase == game.FINISHED, replayLink(l, s.ID)),
+		If(s.Phase == game.PLACING && !s.Suspended && !s.Ready[seat],
+			Div(Class("flex gap-4"),
+				button(l.T("game.shuffle"), htmx.Post(base+"/shuffle"), htmx.Target("#game")),
+				If(s.Placed[seat], button(l.T("game.ready"), htmx.Post(base+"/ready"), htmx.Target("#game"))),
+			),
+		),
+	)
+}
+
+// Page for spectators, showing only what both players know.
+func Spectate(l i18n.Locale, v view.Spectator, delay time.Duration) Node {
+	events := fmt.Sprintf("/games/%s/watch/events", v.ID)
+	if delay > 0 {
+		events += "?delay=" + delay.String()
+	}
+
+	return page(l,
+		Div(Class("w-2/3 flex flex-col items-center gap-4"),
+			htmx.Ext("sse"),
+			Attr("sse-connect", events),
+			If(delay > 0, P(Class("text-sm"), Text(l.T("spectate.delayed", delay)))),
+			Div(ID("spectate"), Class("w-full"),
+				Attr("sse-swap", "spectate"),
+				SpectatorPanel(l, v),
+			),
+			liveRegion("announcement", Attr("sse-swap", "shot"), Announcement(l, v.Snapshot, NO_SEAT)),
+			SpectatorChat(l, v.Snapshot),
+		),
+	)
+}
+
+func SpectatorPanel(l i18n.Locale, v view.Spectator) Node {
+	s := v.Snapshot
+	return Div(Class("w-full flex flex-col items-center gap-4"),
+		H1(Class("text-xl"), Text(spectatorStatus(l, s))),
+		Div(Attr("sse-swap", "clock"), Clocks(l, s)),
+		Div(Class("w-full flex justify-around gap-8"),
+			Map([]int{0, 1}, func(seat int) Node {
+				return Div(Class("w-2/5 flex flex-col items-center gap-2"),
+					H2(Text(l.T("game.fleet", name(l, s, seat)))),
+					board(fmt.Sprintf("fleet-%d", seat), l.T("game.fleet", name(l, s, seat)), func(i int) Node {
+						return BoardCell(l, i, v.Fleets[seat][i])
+					}),
+				)
+			}),
+		),
+		If(s.Phase == game.FINISHED, replayLink(l, s.ID)),
+	)
+}
+
+// Time left for each seat in a timed game. Pushed again every second
+// while the game is played, so it counts down.
+func Clocks(l i18n.Locale, s game.Snapshot) Node {
+	if s.Phase != game.PLAYING || !s.TimeControl.Enabled() {
+		return nil
+	}
+
+	return Div(Class("flex gap-8"),
+		Map([]int{0, 1}, func(seat int) Node {
+			return Span(If(seat == s.Turn, Class("font-bold")), Text(clock(l, s, seat)))
+		}),
+	)
+}
+
+// Page shown while waiting in the matchmaking queue. The queue stream
+// swaps in Matched once an opponent is found, and the same event closes
+// the stream so reconnecting can't queue the player again.
+func Queue(l i18n.Locale) Node {
+	return page(l,
+		Div(Class("flex flex-col items-center gap-4"),
+			htmx.Ext("sse"),
+			Attr("sse-connect", "/play/events"),
+			Attr("sse-close", "matched"),
+			Div(Attr("sse-swap", "matched"),
+				H1(Class("text-xl animate-pulse"), Text(l.T("queue.looking"))),
+			),
+		),
+	)
+}
+
+// Takes the browser to its seat in the game it was matched into.
+func Matched(l i18n.Locale, resume string) Node {
+	return Div(
+		htmx.Get(resume),
+		htmx.Trigger("load"),
+		H1(Class("text-xl"), Text(l.T("queue.found"))),
+	)
+}
+
+// Lists the games that can currently be watched.
+func Watch(l i18n.Locale, games []game.Snapshot) Node {
+	return page(l,
+		Div(Class("flex flex-col items-center gap-4"),
+			H1(Class("text-xl"), Text(l.T("watch.title"))),
+			If(len(games) == 0, P(Text(l.T("watch.empty")))),
+			Ul(
+				Map(games, func(s game.Snapshot) Node {
+					return Li(
+						A(Href(fmt.Sprintf("/games/%s/watch", s.ID)), Class("hover:underline"),
+							Text(l.N("watch.game", len(s.Moves), name(l, s, 0), name(l, s, 1))),
+						),
+					)
+				}),
+			),
+		),
+	)
+}
+
+// The cell at index i of a board, labelled with where it is and what is
+// known about it, such as "B7, miss". Hits and misses are marked with a
+// symbol as well as a colour. action, such as the request firing at the
+// cell, is left off cells already fired at. The first cell is where the
+// keyboard enters the board, see static/board.js.
+func BoardCell(l i18n.Locale, i int, state cell.State, action ...Node) Node {
+	label := l.Coordinate(cell.Coordinate{i % cell.BOARD_WIDTH, i / cell.BOARD_WIDTH})
+	class := "cell"
+	var mark Node
+
+	switch state {
+	case cell.HIT:
+		label, class = l.T("cell.label", label, l.T("cell.hit")), class+" cell-hit"
+		mark = Span(Aria("hidden", "true"), Text("✕"))
+		action = nil
+	case cell.MISS:
+		label, class = l.T("cell.label", label, l.T("cell.miss")), class+" cell-miss"
+		mark = Span(Aria("hidden", "true"), Text("•"))
+		action = nil
+	case cell.SHIP:
+		label, class = l.T("cell.label", label, l.T("cell.ship")), class+" cell-ship"
+	}
+
+	if len(action) > 0 {
+		class += " cell-target"
+	}
+
+	tabindex := "-1"
+	if i == 0 {
+		tabindex = "0"
+	}
+
+	return Div(Role("gridcell"), Aria("label", label), Class(class), TabIndex(tabindex), Group(action), mark)
+}
+
+// Lays out a BOARD_WIDTH x BOARD_HEIGHT grid of cells, a row at a time so
+// screen readers can tell where each cell is.
+func board(id, label string, cell_at func(i int) Node) Node {
+	rows := make(Group, cell.BOARD_HEIGHT)
+	for y := range rows {
+		cells := make(Group, cell.BOARD_WIDTH)
+		for x := range cells {
+			cells[x] = cell_at(y*cell.BOARD_WIDTH + x)
+		}
+		rows[y] = Div(Role("row"), Class("board-row"), cells)
+	}
+	return Div(ID(id), Role("grid"), Aria("label", label), Class("w-full grid grid-cols-10 gap-2"), rows)
+}
+
+// Says what the last shot in s hit, for the player in seat or, if seat
+// is NO_SEAT, for spectators. Shown in a live region, so screen readers
+// read it out as each shot comes in.
+func Announcement(l i18n.Locale, s game.Snapshot, seat int) Node {
+	if len(s.Moves) == 0 {
+		return Group{}
+	}
+
+	move := s.Moves[len(s.Moves)-1]
+	at := l.Coordinate(move.Coordinate)
+	result := l.T("cell.miss")
+	if move.Hit {
+		result = l.T("cell.hit")
+	}
+
+	var text string
+	switch seat {
+	case NO_SEAT:
+		text = l.T("announce.player", name(l, s, move.Seat), at, result)
+	case move.Seat:
+		text = l.T("announce.you", at, result)
+	default:
+		text = l.T("announce.opponent", at, result)
+	}
+
+	if s.Phase == game.FINISHED {
+		if seat == NO_SEAT {
+			text = l.T("announce.end", text, spectatorStatus(l, s))
+		} else {
+			text = l.T("announce.end", text, status(l, s, seat))
+		}
+	}
+
+	return Text(text)
+}
+
+func liveRegion(id string, children ...Node) Node {
+	return Div(ID(id), Role("status"), Aria("live", "polite"), Class("text-sm"), Group(children))
+}
+
+func replayLink(l i18n.Locale, id string) Node {
+	return A(Href(fmt.Sprintf("/games/%s/replay", id)), Class("hover:underline"), Text(l.T("game.replay")))
+}
+
+func button(label string, children ...Node) Node {
+	return Button(Class("rounded border px-4 py-2 hover:bg-blue-500"), Group(children), Text(label))
+}
+
+func name(l i18n.Locale, s game.Snapshot, seat int) string {
+	if !s.Joined[seat] {
+		return l.T("game.waiting")
+	}
+	return s.Names[seat]
+}
+
+func status(l i18n.Locale, s game.Snapshot, seat int) string {
+	switch {
+	case !s.Joined[1-seat]:
+		return l.T("status.no_opponent")
+	case s.Phase == game.PLACING && s.Ready[seat]:
+		return l.T("status.placing_wait")
+	case s.Phase == game.PLACING:
+		return l.T("status.placing")
+	case s.Phase == game.FINISHED && s.Winner == seat && s.Forfeited:
+		return l.T("status.won_forfeit")
+	case s.Phase == game.FINISHED && s.Winner == seat:
+		return l.T("status.won")
+	case s.Phase == game.FINISHED && s.Forfeited:
+		return l.T("status.lost_forfeit")
+	case s.Phase == game.FINISHED:
+		return l.T("status.lost")
+	case s.Turn == seat:
+		return l.T("status.your_turn")
+	default:
+		return l.T("status.their_turn")
+	}
+}
+
+func away(l i18n.Locale, s game.Snapshot, seat int) string {
+	if s.ForfeitAt[seat].IsZero() {
+		return l.T("game.reconnecting")
+	}
+
+	left := time.Until(s.ForfeitAt[seat]).Round(time.Second)
+	return l.T("game.forfeit_in", max(left, 0))
+}
+
+func clock(l i18n.Locale, s game.Snapshot, seat int) string {
+	text := name(l, s, seat)
+	if s.TimeControl.Clock > 0 {
+		text += " " + formatDuration(s.Clocks[seat])
+	}
+
+	if seat == s.Turn && !s.Deadline.IsZero() {
+		left := max(time.Until(s.Deadline), 0)
+		text += l.T("game.to_fire", formatDuration(left))
+	}
+
+	return text
+}
+
+// Formats d like a clock, as minutes and seconds.
+func formatDuration(d time.Duration) string {
+	seconds := int(d.Round(time.Second).Seconds())
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+func spectatorStatus(l i18n.Locale, s game.Snapshot) string {
+	switch s.Phase {
+	case game.PLACING:
+		return l.T("spectate.placing")
+	case game.FINISHED:
+		return l.T("spectate.won", name(l, s, s.Winner))
+	default:
+		return l.T("spectate.to_fire", name(l, s, s.Turn))
+	}
+}
@@ -2,9 +2,15 @@ package html
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 
-	"github.com/alfiehiscox/submarines/pkg/board"
+	"github.com/alfiehiscox/submarines/pkg/account"
 	"github.com/alfiehiscox/submarines/pkg/cell"
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/alfiehiscox/submarines/pkg/i18n"
+	"github.com/alfiehiscox/submarines/pkg/view"
+	"github.com/alfiehiscox/submarines/static"
 	. "maragu.dev/gomponents"
 	htmx "maragu.dev/gomponents-htmx"
 	. "maragu.dev/gomponents/components"
@@ -14,25 +20,118 @@ import (
 const (
 	HTMX_SOURCE    = "https://unpkg.com/htmx.org@2.0.3"
 	HTMX_INTEGRITY = "sha384-0895/pl2MU10Hqc6jd4RvrthNlDiE9U1tWmX7WRESftEDRosgxNsQG/Ze9YMRzHq"
+
+	HTMX_SSE_SOURCE = "https://unpkg.com/htmx-ext-sse@2.2.2/sse.js"
+
+	// Copies of the above served by the site, see `make vendor`
+	HTMX_VENDORED     = "vendor/htmx.min.js"
+	HTMX_SSE_VENDORED = "vendor/sse.js"
+
+	// Keeps htmx within the site's Content Security Policy: it doesn't add
+	// its own inline styles, which app.css replaces, or run scripts from
+	// attributes and swapped in content
+	HTMX_CONFIG = `{"includeIndicatorStyles":false,"allowEval":false,"allowScriptTags":false}`
+
+	// Query parameter choosing the language pages are shown in
+	LANGUAGE_PARAM = "lang"
 )
 
-func Index() Node {
-	return page(H1(Class("text-xl"), Text("Battleships")))
+// The static files pages link to. Replaced when serving them from disk
+// while developing.
+var Assets = static.Embedded()
+
+// The home page. a is the logged in account, nil if there isn't one.
+func Index(l i18n.Locale, a *account.Account) Node {
+	return page(l,
+		Div(Class("flex flex-col items-center gap-4"),
+			H1(Class("text-xl"), Text(l.T("site.title"))),
+			button(l.T("index.new_game"), htmx.Post("/games")),
+			A(Href("/play"), Class("rounded border px-4 py-2 hover:bg-blue-500"), Text(l.T("index.play_online"))),
+			A(Href("/watch"), Class("hover:underline"), Text(l.T("index.watch"))),
+			A(Href("/history"), Class("hover:underline"), Text(l.T("index.history"))),
+			A(Href("/leaderboard"), Class("hover:underline"), Text(l.T("index.leaderboard"))),
+			accountBar(l, a),
+		),
+	)
 }
 
-func PlaceShips(board board.Board) Node {
-	return page(
-		Div(Class("w-1/3 h-screen flex flex-col items-center justify-center"),
-			ShipGallery(0),
-			Div(Class("w-4/5 grid grid-cols-10 gap-2"),
-				Map(board, func(cell cell.Cell) Node {
-					return Cell(cell.Occupied, "hover:bg-blue-500")
-				}),
-			),
+// Page for placing ships, one at a time, by choosing a ship and then the
+// square its top left end goes on.
+func PlaceShips(l i18n.Locale, p view.Placement) Node {
+	return page(l,
+		Div(Class("w-1/3 h-screen flex flex-col items-center justify-center gap-4"),
+			H1(Class("text-xl"), Text(l.T("place.title"))),
+			P(Class("text-sm"), Text(l.T("place.help"))),
+			liveRegion("placement-status", Text(placed(l, p))),
+			placement(l, p, false),
+		),
+	)
+}
+
+// The form placing the next ship, swapped in as each one is placed along
+// with a status saying where it went.
+func Placement(l i18n.Locale, p view.Placement) Node {
+	return placement(l, p, true)
+}
+
+func placement(l i18n.Locale, p view.Placement, oob bool) Node {
+	hidden := make(Group, 0, len(p.Ships))
+	for id := 1; id <= len(game.FLEET); id++ {
+		if ship, ok := p.Ships[id]; ok {
+			value := fmt.Sprintf("%d,%d,%s", ship.Coordinate[0], ship.Coordinate[1], ship.Orientation)
+			hidden = append(hidden, Input(Type("hidden"), Name(fmt.Sprintf("ship%d", id)), Value(value)))
+		}
+	}
+
+	return Form(ID("placement"), Class("w-full flex flex-col items-center gap-4"),
+		ShipGallery(l, p.Chosen),
+		FieldSet(Class("flex gap-4"),
+			Legend(Text(l.T("place.orientation"))),
+			orientation(l.T("place.horizontal"), cell.HORIZONTAL, p.Orientation),
+			orientation(l.T("place.vertical"), cell.VERTICAL, p.Orientation),
 		),
+		hidden,
+		board("placement-board", l.T("place.title"), func(i int) Node {
+			if p.Chosen == 0 {
+				return BoardCell(l, i, p.Fleet[i])
+			}
+			x, y := i%cell.BOARD_WIDTH, i/cell.BOARD_WIDTH
+			return BoardCell(l, i, p.Fleet[i],
+				htmx.Post(fmt.Sprintf("/place-ships/%d/%d", x, y)),
+				htmx.Target("#placement"),
+				htmx.Swap("outerHTML"),
+			)
+		}),
+		If(oob, Div(ID("placement-status"), htmx.SwapOOB("innerHTML"), Text(placed(l, p)))),
 	)
 }
 
+func orientation(label string, value, chosen cell.Orientation) Node {
+	return Label(Class("flex items-center gap-2"),
+		Input(Type("radio"), Name("orientation"), Value(string(value)), If(value == chosen, Checked())),
+		Text(label),
+	)
+}
+
+// Says where the last ship went, if one has just been placed.
+func placed(l i18n.Locale, p view.Placement) string {
+	ship, ok := p.Ships[p.Last]
+	if !ok {
+		return ""
+	}
+
+	direction := l.T("place.horizontal")
+	if ship.Orientation == cell.VERTICAL {
+		direction = l.T("place.vertical")
+	}
+
+	text := l.T("place.placed", l.Ship(p.Last), l.Coordinate(ship.Coordinate), direction)
+	if p.Chosen == 0 {
+		text = l.T("announce.end", text, l.T("place.done"))
+	}
+	return text
+}
+
 func Repeat(n int, node Node) Node {
 	group := make(Group, n)
 	for i := range group {
@@ -41,59 +140,134 @@ func Repeat(n int, node Node) Node {
 	return group
 }
 
-func ShipGallery(chosen int) Node {
-	return Div(ID("ship-gallery"),
+// The fleet's ships, largest first, as a choice of which to place with
+// the chosen one selected.
+func ShipGallery(l i18n.Locale, chosen int) Node {
+	ships := make(Group, len(game.FLEET))
+	for i, size := range game.FLEET {
+		ships[i] = Ship(l, i+1, size, chosen)
+	}
+
+	return FieldSet(ID("ship-gallery"),
 		Class("w-full h-16 flex justify-around items-center"),
-		Ship(1, 5, chosen),
-		Ship(2, 4, chosen),
-		Ship(3, 4, chosen),
-		Ship(4, 3, chosen),
-		Ship(5, 2, chosen),
+		Legend(Class("sr-only"), Text(l.T("place.ships"))),
+		ships,
 	)
 }
 
-func Ship(id, count, chosen int) Node {
-	var style string
-	if id == chosen {
-		style = "bg-blue-500"
-	} else {
-		style = "group-hover:bg-blue-500"
-	}
-
-	return Div(
-		htmx.Get(fmt.Sprintf("/ship-select/%d", id)),
-		htmx.Trigger("click"),
-		htmx.Swap("outerHTML"),
-		htmx.Target("#ship-gallery"),
-		Class("w-1/5 flex justify-center items-center group"),
-		Repeat(count, Cell(false, style)),
+func Ship(l i18n.Locale, id, count, chosen int) Node {
+	return Label(
+		Class("ship w-1/5 flex justify-center items-center group cursor-pointer"),
+		Title(l.Ship(id)),
+		Input(Type("radio"), Name("ship"), Value(strconv.Itoa(id)), Class("sr-only"),
+			Aria("label", l.N("place.ship", count, l.Ship(id))),
+			If(id == chosen, Checked()),
+		),
+		Repeat(count, Cell(false, "ship-square group-hover:bg-blue-500")),
 	)
 }
 
-func Cell(occupied bool, style string) Node {
-	if occupied {
+// A plain square, such as a ship in the gallery or a shade of a heatmap.
+// Boards are drawn with BoardCell, from a view of what the viewer knows.
+func Cell(filled bool, style string) Node {
+	if filled {
 		return Div(Class("rounded w-4 h-4 " + style))
-	} else {
-		return Div(
-			Class("rounded border w-5 h-5 " + style),
-		)
 	}
+	return Div(Class("rounded border w-5 h-5 " + style))
 }
 
-func page(children ...Node) Node {
+// Returns the scripts pages load from other sites, which the Content
+// Security Policy has to allow.
+func ScriptSources() []string {
+	if vendored() {
+		return nil
+	}
+	return []string{HTMX_SOURCE, HTMX_SSE_SOURCE}
+}
+
+func vendored() bool {
+	return Assets.Has(HTMX_VENDORED) && Assets.Has(HTMX_SSE_VENDORED)
+}
+
+// Loads htmx from the site if it has been vendored, otherwise from the
+// CDN.
+func htmxScripts() Node {
+	if vendored() {
+		return Group{
+			Script(Src(Assets.URL(HTMX_VENDORED))),
+			Script(Src(Assets.URL(HTMX_SSE_VENDORED))),
+		}
+	}
+
+	return Group{
+		Script(Src(HTMX_SOURCE), Integrity(HTMX_INTEGRITY), CrossOrigin("anonymous")),
+		Script(Src(HTMX_SSE_SOURCE), CrossOrigin("anonymous")),
+	}
+}
+
+// Lays out a fragment that is usually swapped into another page, for
+// when it is loaded on its own such as from a bookmark.
+func Page(l i18n.Locale, fragment Node) Node {
+	return page(l, fragment)
+}
+
+func page(l i18n.Locale, children ...Node) Node {
 	return HTML5(HTML5Props{
-		Title:       "battleships",
-		Description: "battleships",
-		Language:    "en",
+		Title:       l.T("site.title"),
+		Description: l.T("site.description"),
+		Language:    l.Tag(),
 		Head: []Node{
-			Link(Rel("stylesheet"), Href("static/app.css")),
-			Script(Src(HTMX_SOURCE), Integrity(HTMX_INTEGRITY), CrossOrigin("anonymous")),
+			Meta(Name("htmx-config"), Content(HTMX_CONFIG)),
+			Link(Rel("stylesheet"), Href(Assets.URL("app.css"))),
+			htmxScripts(),
+			Script(Src(Assets.URL("csrf.js"))),
+			Script(Src(Assets.URL("errors.js"))),
+			Script(Src(Assets.URL("board.js"))),
 		},
 		Body: []Node{
+			// Where htmx requests show what went wrong
+			Div(ID("error"), Role("alert"), Class("fixed inset-x-0 top-4 flex justify-center")),
 			Div(
 				Class("w-full h-screen flex justify-center items-center"),
 				Group(children),
 			),
+			languages(l),
 		},
 	})
 }
+
+// Links to the page in each language. The choice is remembered, taking
+// over from the browser's preferences.
+func languages(current i18n.Locale) Node {
+	return Nav(Class("fixed inset-x-0 bottom-4 flex justify-center gap-4 text-sm"), Aria("label", current.T("site.language")),
+		Map(i18n.Locales(), func(l i18n.Locale) Node {
+			if l.Tag() == current.Tag() {
+				return Span(Lang(l.Tag()), Aria("current", "true"), Class("font-bold"), Text(l.Name()))
+			}
+			return A(Href("?"+LANGUAGE_PARAM+"="+l.Tag()), Lang(l.Tag()), Class("hover:underline"), Text(l.Name()))
+		}),
+	)
+}
+
+var verb = regexp.MustCompile(`%(?:\[(\d+)\])?s`)
+
+// Lays out a translated message with its %s verbs, such as %[2]s, filled
+// by nodes, so links can sit wherever the translation puts them.
+func sentence(message string, nodes ...Node) Node {
+	var group Group
+	next := 0
+	for n, match := range verb.FindAllStringSubmatchIndex(message, -1) {
+		group = append(group, Text(message[next:match[0]]))
+		next = match[1]
+
+		i := n
+		if match[2] >= 0 {
+			i, _ = strconv.Atoi(message[match[2]:match[3]])
+			i--
+		}
+		if i >= 0 && i < len(nodes) {
+			group = append(group, nodes[i])
+		}
+	}
+	return append(group, Text(message[next:]))
+}
@@ -0,0 +1,88 @@
+package html
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/alfiehiscox/submarines/pkg/account"
+	"github.com/alfiehiscox/submarines/pkg/i18n"
+	"github.com/alfiehiscox/submarines/pkg/rating"
+	"github.com/alfiehiscox/submarines/pkg/stats"
+	. "maragu.dev/gomponents"
+	. "maragu.dev/gomponents/html"
+)
+
+// Shades of a heatmap cell, from least to most
+var heat = []string{"", "bg-blue-500/20", "bg-blue-500/40", "bg-blue-500/60", "bg-blue-500/80", "bg-blue-500"}
+
+// A player's profile: their record, rating over time and where they
+// like to place ships and fire.
+func Player(l i18n.Locale, a account.Account, s stats.Stats, history []rating.Change) Node {
+	return page(l,
+		Div(Class("flex flex-col items-center gap-4"),
+			H1(Class("text-xl"), Text(a.Name), If(a.Bot, Text(l.T("account.bot")))),
+			If(s.Played == 0, P(Text(l.T("player.no_games")))),
+			If(s.Played > 0,
+				Dl(Class("grid grid-cols-2 gap-x-4"),
+					stat(l.T("player.played"), fmt.Sprint(s.Played)),
+					stat(l.T("player.won_lost"), fmt.Sprintf("%d / %d", s.Wins, s.Losses)),
+					stat(l.T("player.accuracy"), fmt.Sprintf("%.0f%%", s.Accuracy()*100)),
+					If(s.Wins > 0, stat(l.T("player.shots_to_win"), fmt.Sprintf("%.1f", s.ShotsToWin()))),
+					If(len(s.Openings) > 0, stat(l.T("player.openings"), openings(l, s.Openings))),
+				),
+			),
+			If(len(history) > 0,
+				Group{
+					H2(Text(l.T("player.rating", formatRating(history[len(history)-1].Rating)))),
+					RatingGraph(history),
+				},
+			),
+			If(s.Played > 0,
+				Div(Class("flex gap-8"),
+					Div(Class("flex flex-col items-center gap-2"),
+						H2(Text(l.T("player.placements"))),
+						Heatmap(s.Placements),
+					),
+					Div(Class("flex flex-col items-center gap-2"),
+						H2(Text(l.T("player.fired"))),
+						Heatmap(s.Fired),
+					),
+				),
+			),
+		),
+	)
+}
+
+// Shades each cell of a board by how its count compares to the highest.
+func Heatmap(counts [stats.CELLS]int) Node {
+	most := 0
+	for _, count := range counts {
+		most = max(most, count)
+	}
+
+	cells := make([]Node, len(counts))
+	for i, count := range counts {
+		shade := 0
+		if most > 0 {
+			shade = int(math.Ceil(float64(count) / float64(most) * float64(len(heat)-1)))
+		}
+		cells[i] = Cell(count > 0, heat[shade])
+	}
+
+	return Div(Class("grid grid-cols-10 gap-2"), Group(cells))
+}
+
+func stat(label, value string) Node {
+	return Group{Dt(Text(label)), Dd(Class("text-right"), Text(value))}
+}
+
+func openings(l i18n.Locale, o []stats.Opening) string {
+	text := ""
+	for i, opening := range o {
+		if i > 0 {
+			text += ", "
+		}
+		text += l.Coordinate(opening.Coordinate)
+	}
+	return text
+}
@@ -0,0 +1,107 @@
+package html
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+
+	"github.com/alfiehiscox/submarines/pkg/account"
+	"github.com/alfiehiscox/submarines/pkg/i18n"
+	"github.com/alfiehiscox/submarines/pkg/rating"
+	. "maragu.dev/gomponents"
+	. "maragu.dev/gomponents/html"
+)
+
+const (
+	GRAPH_WIDTH  = 400
+	GRAPH_HEIGHT = 200
+)
+
+// An account's place on the leaderboard.
+type Standing struct {
+	Account account.Account
+	Rating  rating.Rating
+}
+
+func Leaderboard(l i18n.Locale, standings []Standing) Node {
+	cell := "px-4 py-1"
+
+	rows := make([]Node, len(standings))
+	for i, s := range standings {
+		rows[i] = Tr(
+			Td(Class(cell), Text(fmt.Sprint(i+1))),
+			Td(Class(cell), playerLink(l, s.Account)),
+			Td(Class(cell), Text(formatRating(s.Rating))),
+		)
+	}
+
+	return page(l,
+		Div(Class("flex flex-col items-center gap-4"),
+			H1(Class("text-xl"), Text(l.T("leaderboard.title"))),
+			If(len(standings) == 0, P(Text(l.T("leaderboard.empty")))),
+			If(len(standings) > 0,
+				Table(
+					THead(Tr(
+						Th(Class(cell), Text("#")),
+						Th(Class(cell), Text(l.T("leaderboard.player"))),
+						Th(Class(cell), Text(l.T("leaderboard.rating"))),
+					)),
+					TBody(Group(rows)),
+				),
+			),
+		),
+	)
+}
+
+// Plots rating over a player's games, with a band showing the deviation.
+func RatingGraph(history []rating.Change) Node {
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, change := range history {
+		low = math.Min(low, change.Rating.Rating-change.Rating.Deviation)
+		high = math.Max(high, change.Rating.Rating+change.Rating.Deviation)
+	}
+
+	x := func(i int) float64 {
+		if len(history) == 1 {
+			return GRAPH_WIDTH / 2
+		}
+		return float64(i) / float64(len(history)-1) * GRAPH_WIDTH
+	}
+	y := func(value float64) float64 {
+		return GRAPH_HEIGHT - (value-low)/(high-low)*GRAPH_HEIGHT
+	}
+
+	line := make([]string, len(history))
+	band := make([]string, 2*len(history))
+	for i, change := range history {
+		r := change.Rating
+		line[i] = fmt.Sprintf("%.1f,%.1f", x(i), y(r.Rating))
+		band[i] = fmt.Sprintf("%.1f,%.1f", x(i), y(r.Rating+r.Deviation))
+		band[len(band)-1-i] = fmt.Sprintf("%.1f,%.1f", x(i), y(r.Rating-r.Deviation))
+	}
+
+	return Div(Class("flex gap-2"),
+		Div(Class("flex flex-col justify-between text-sm"),
+			Span(Text(fmt.Sprintf("%.0f", high))),
+			Span(Text(fmt.Sprintf("%.0f", low))),
+		),
+		SVG(
+			Attr("viewBox", fmt.Sprintf("0 0 %d %d", GRAPH_WIDTH, GRAPH_HEIGHT)),
+			Attr("width", fmt.Sprint(GRAPH_WIDTH)),
+			Attr("height", fmt.Sprint(GRAPH_HEIGHT)),
+			Class("border rounded"),
+			El("polygon", Attr("points", strings.Join(band, " ")), Attr("fill", "rgb(59 130 246 / 0.2)")),
+			El("polyline", Attr("points", strings.Join(line, " ")), Attr("fill", "none"), Attr("stroke", "rgb(59 130 246)"), Attr("stroke-width", "2")),
+		),
+	)
+}
+
+func playerLink(l i18n.Locale, a account.Account) Node {
+	return A(Href("/players/"+url.PathEscape(a.Name)), Class("hover:underline"), Text(a.Name), If(a.Bot, Text(l.T("account.bot"))))
+}
+
+// Shows a rating with its deviation, like 1520 ± 80.
+func formatRating(r rating.Rating) string {
+	return fmt.Sprintf("%.0f ± %.0f", r.Rating, r.Deviation)
+}
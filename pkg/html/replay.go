@@ -0,0 +1,113 @@
+package html
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/alfiehiscox/submarines/pkg/i18n"
+	"github.com/alfiehiscox/submarines/pkg/view"
+	. "maragu.dev/gomponents"
+	htmx "maragu.dev/gomponents-htmx"
+	. "maragu.dev/gomponents/html"
+)
+
+// Time between moves when a replay plays itself
+const AUTOPLAY_DELAY = time.Second
+
+// Lists finished games, newest first, each linking to its replay.
+func History(l i18n.Locale, records []game.Record) Node {
+	return page(l,
+		Div(Class("flex flex-col items-center gap-4"),
+			H1(Class("text-xl"), Text(l.T("history.title"))),
+			If(len(records) == 0, P(Text(l.T("history.empty")))),
+			Ul(Class("flex flex-col gap-2"),
+				Map(records, func(r game.Record) Node {
+					return Li(
+						A(Href(fmt.Sprintf("/games/%s/replay", r.ID)), Class("hover:underline"),
+							Text(l.T("history.game", r.Seats[0].Name, r.Seats[1].Name)),
+						),
+						Text(l.N("history.result", len(r.Moves), r.Seats[r.Winner].Name)),
+					)
+				}),
+			),
+		),
+	)
+}
+
+// Page stepping through a finished game, starting after the moves in v
+// and playing itself if autoplay is set.
+func Replay(l i18n.Locale, v view.Replay, autoplay bool) Node {
+	return page(l,
+		Div(Class("w-2/3 flex flex-col items-center gap-4"),
+			ReplayPanel(l, v, autoplay),
+		),
+	)
+}
+
+// Both fleets after the first moves of a finished game, with controls
+// that swap in the panel for other moves. While autoplaying the panel
+// fetches the next move itself.
+func ReplayPanel(l i18n.Locale, v view.Replay, autoplay bool) Node {
+	n := v.N
+	last := len(v.Moves)
+	autoplay = autoplay && n < last
+
+	step := func(label string, to int, autoplay bool, disabled bool) Node {
+		url := fmt.Sprintf("/games/%s/replay/%d", v.ID, to)
+		if autoplay {
+			url += "?autoplay=true"
+		}
+		return button(label, htmx.Get(url), htmx.Target("#replay"), htmx.Swap("outerHTML"), If(disabled, Disabled()))
+	}
+
+	return Div(ID("replay"), Class("w-full flex flex-col items-center gap-4"),
+		If(autoplay, Group{
+			htmx.Get(fmt.Sprintf("/games/%s/replay/%d?autoplay=true", v.ID, n+1)),
+			htmx.Trigger(fmt.Sprintf("load delay:%dms", AUTOPLAY_DELAY.Milliseconds())),
+			htmx.Swap("outerHTML"),
+		}),
+		H1(Class("text-xl"), Text(replayStatus(l, v))),
+		Div(Class("w-full flex justify-around gap-8"),
+			Map([]int{0, 1}, func(seat int) Node {
+				return Div(Class("w-2/5 flex flex-col items-center gap-2"),
+					H2(Text(l.T("game.fleet", v.Names[seat]))),
+					board(fmt.Sprintf("replay-%d", seat), l.T("game.fleet", v.Names[seat]), func(i int) Node {
+						return BoardCell(l, i, v.Fleets[seat][i])
+					}),
+				)
+			}),
+		),
+		Div(Class("flex gap-4"),
+			step(l.T("replay.first"), 0, false, n == 0),
+			step(l.T("replay.previous"), n-1, false, n == 0),
+			If(autoplay, step(l.T("replay.pause"), n, false, false)),
+			If(!autoplay, step(l.T("replay.play"), n+1, true, n == last)),
+			step(l.T("replay.next"), n+1, false, n == last),
+			step(l.T("replay.last"), last, false, n == last),
+		),
+	)
+}
+
+func replayStatus(l i18n.Locale, v view.Replay) string {
+	n := v.N
+	if n == 0 {
+		return l.T("history.game", v.Names[0], v.Names[1])
+	}
+
+	move := v.Moves[n-1]
+	result := l.T("replay.missed")
+	if move.Hit {
+		result = l.T("replay.hit")
+	}
+
+	status := l.T("replay.move", n, len(v.Moves), v.Names[move.Seat], l.Coordinate(move.Coordinate), result)
+	if n == len(v.Moves) && v.Winner != game.NO_WINNER {
+		if v.Forfeited {
+			status += l.T("replay.won_forfeit", v.Names[v.Winner])
+		} else {
+			status += l.T("replay.won", v.Names[v.Winner])
+		}
+	}
+	return status
+}
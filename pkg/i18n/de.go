@@ -0,0 +1,193 @@
+package i18n
+
+var de = map[string]string{
+	"site.title":       "Schiffe versenken",
+	"site.description": "Versenke die Flotte deines Gegners, bevor er deine versenkt",
+	"site.language":    "Sprache",
+
+	"index.new_game":    "Neues Spiel",
+	"index.play_online": "Online spielen",
+	"index.watch":       "Laufende Spiele ansehen",
+	"index.history":     "Beendete Spiele",
+	"index.leaderboard": "Rangliste",
+
+	"account.register":         "Registrieren",
+	"account.register_note":    "Spiele, die du in diesem Browser als Gast gespielt hast, bleiben erhalten",
+	"account.login":            "Anmelden",
+	"account.no_account":       "Kein Konto? Registrieren",
+	"account.anonymous":        "%[1]s oder %[2]s",
+	"account.register_link":    "registrieren",
+	"account.guest":            "Du spielst als %[1]s. %[2]s, um deine Spiele zu behalten, oder %[3]s",
+	"account.login_link":       "anmelden",
+	"account.logged_in":        "Angemeldet als %[1]s.",
+	"account.logout":           "Abmelden",
+	"account.name":             "Name",
+	"account.password":         "Passwort",
+	"account.bot":              " (Bot)",
+	"account.registered":       "Du bist bereits registriert, melde dich zuerst ab",
+	"account.name_taken":       "Dieser Name ist vergeben",
+	"account.invalid_name":     "Namen haben 3 bis 20 Buchstaben, Ziffern, - oder _",
+	"account.invalid_password": "Passwörter haben %[1]d bis %[2]d Zeichen",
+	"account.wrong_password":   "falscher Name oder falsches Passwort",
+
+	"chat.title":         "Chat",
+	"chat.placeholder":   "Schreib etwas",
+	"chat.send":          "Senden",
+	"chat.mute":          "Stummschalten",
+	"chat.unmute":        "Stummschaltung aufheben",
+	"chat.report":        "Melden",
+	"chat.reported":      "Gemeldet, danke",
+	"chat.muted":         "%[1]s ist stummgeschaltet",
+	"chat.empty":         "Noch keine Nachrichten",
+	"chat.empty_message": "die Nachricht ist leer",
+	"chat.too_long":      "Nachrichten haben höchstens %[1]d Zeichen",
+	"chat.too_fast":      "du sendest zu schnell, warte einen Moment",
+	"chat.closed":        "der Chat ist geschlossen",
+
+	"error.request_id":       "Anfrage-ID: %[1]s",
+	"error.home":             "Zurück zur Startseite",
+	"error.server":           "Entschuldigung, etwas ist schiefgelaufen. Bitte versuche es erneut.",
+	"error.not_seated":       "Du sitzt nicht in diesem Spiel",
+	"error.invalid_token":    "Dieser Link zum Fortsetzen ist ungültig",
+	"error.banned":           "zu viele ungültige Züge, versuche es später erneut",
+	"error.rate_limited":     "zu viele Anfragen, langsamer bitte",
+	"error.invalid_request":  "ungültiger Anfrageinhalt",
+	"error.unauthorized":     "Sitz-Token fehlt oder ist ungültig",
+	"error.game_not_found":   "Spiel nicht gefunden",
+	"error.game_full":        "das Spiel ist voll",
+	"error.game_in_progress": "das Spiel läuft noch",
+	"error.wrong_phase":      "in dieser Phase nicht erlaubt",
+	"error.suspended":        "das Spiel ist pausiert, während der Server neu startet",
+	"error.player_not_found": "Spieler nicht gefunden",
+	"error.coordinates":      "Koordinaten müssen Zahlen sein",
+	"error.coordinate":       "dieses Feld liegt nicht auf dem Spielbrett",
+	"error.off_board":        "das ginge über den Rand des Spielbretts",
+	"error.occupied":         "Schiffe dürfen sich nicht überlappen",
+	"error.invalid_fleet":    "ungültige Flotte",
+	"error.already_ready":    "die Flotte ist bereits festgelegt",
+	"error.fleet_not_ready":  "die Flotte wurde noch nicht platziert",
+	"error.not_your_turn":    "du bist nicht am Zug",
+	"error.already_fired":    "auf dieses Feld wurde schon geschossen",
+	"error.form":             "ungültiges Formular",
+	"error.choose_ship":      "wähle ein Schiff zum Platzieren",
+	"error.orientation":      "wähle, in welche Richtung das Schiff zeigt",
+	"error.placement":        "ungültige Schiffsplatzierung",
+	"error.ship_fit":         "das Schiff passt dort nicht hin",
+	"error.ship_id":          "die Schiffs-ID muss eine Zahl sein",
+	"error.ship_unavailable": "Schiffs-ID nicht verfügbar",
+	"error.move":             "der Zug muss eine Zahl sein",
+	"error.delay":            "die Verzögerung muss eine Dauer wie 30s sein",
+	"error.delay_range":      "Verzögerung außerhalb des erlaubten Bereichs",
+
+	"http.400": "Ungültige Anfrage",
+	"http.403": "Verboten",
+	"http.404": "Nicht gefunden",
+	"http.409": "Konflikt",
+	"http.429": "Zu viele Anfragen",
+	"http.500": "Interner Serverfehler",
+	"http.503": "Dienst nicht verfügbar",
+
+	"game.resume":       "Link zum Fortsetzen: ",
+	"game.waiting_for":  "%[1]s wartet auf einen Gegner",
+	"game.join":         "Spiel beitreten",
+	"game.share":        "Teile diesen Link mit deinem Gegner: ",
+	"game.restarting":   "Der Server startet neu, das Spiel geht gleich weiter",
+	"game.your_fleet":   "Deine Flotte",
+	"game.enemy_waters": "Feindliche Gewässer",
+	"game.fleet":        "Flotte von %[1]s",
+	"game.shuffle":      "Mischen",
+	"game.ready":        "Bereit",
+	"game.replay":       "Wiederholung ansehen",
+	"game.waiting":      "Wartet",
+	"game.to_fire":      " (%[1]s zum Schießen)",
+	"game.reconnecting": "Warte darauf, dass sich dein Gegner wieder verbindet",
+	"game.forfeit_in":   "Warte darauf, dass sich dein Gegner wieder verbindet, er gibt in %[1]s auf",
+
+	"status.no_opponent":  "Warte auf einen Gegner",
+	"status.placing_wait": "Warte darauf, dass dein Gegner seine Flotte aufstellt",
+	"status.placing":      "Stelle deine Flotte auf",
+	"status.won_forfeit":  "Du hast gewonnen! Dein Gegner hat aufgegeben",
+	"status.won":          "Du hast gewonnen!",
+	"status.lost_forfeit": "Du hast durch Aufgabe verloren",
+	"status.lost":         "Du hast verloren",
+	"status.your_turn":    "Du bist dran",
+	"status.their_turn":   "Dein Gegner ist dran",
+
+	"spectate.delayed": "Um %[1]s verzögert",
+	"spectate.placing": "Die Spieler stellen ihre Flotten auf",
+	"spectate.won":     "%[1]s hat gewonnen!",
+	"spectate.to_fire": "%[1]s schießt",
+
+	"queue.looking": "Suche einen Gegner",
+	"queue.found":   "Gegner gefunden",
+
+	"watch.title":      "Laufende Spiele",
+	"watch.empty":      "Keine laufenden Spiele",
+	"watch.game.one":   "%[2]s gegen %[3]s (%[1]d Schuss)",
+	"watch.game.other": "%[2]s gegen %[3]s (%[1]d Schüsse)",
+
+	"history.title":        "Beendete Spiele",
+	"history.empty":        "Noch kein Spiel ist beendet",
+	"history.game":         "%[1]s gegen %[2]s",
+	"history.result.one":   ", %[2]s gewann in %[1]d Zug",
+	"history.result.other": ", %[2]s gewann in %[1]d Zügen",
+
+	"replay.first":       "Erster",
+	"replay.previous":    "Zurück",
+	"replay.pause":       "Pause",
+	"replay.play":        "Abspielen",
+	"replay.next":        "Weiter",
+	"replay.last":        "Letzter",
+	"replay.move":        "Zug %[1]d von %[2]d: %[3]s schoss auf %[4]s und %[5]s",
+	"replay.hit":         "traf",
+	"replay.missed":      "verfehlte",
+	"replay.won":         ". %[1]s gewann",
+	"replay.won_forfeit": ". %[1]s gewann durch Aufgabe",
+
+	"player.no_games":     "Noch keine beendeten Spiele",
+	"player.played":       "Gespielte Spiele",
+	"player.won_lost":     "Gewonnen / verloren",
+	"player.accuracy":     "Trefferquote",
+	"player.shots_to_win": "Schüsse bis zum Sieg",
+	"player.openings":     "Lieblingseröffnungen",
+	"player.rating":       "Wertung %[1]s",
+	"player.placements":   "Wo Schiffe platziert werden",
+	"player.fired":        "Wohin geschossen wird",
+
+	"leaderboard.title":  "Rangliste",
+	"leaderboard.empty":  "Noch niemand hat ein gewertetes Spiel beendet",
+	"leaderboard.player": "Spieler",
+	"leaderboard.rating": "Wertung",
+
+	"cell.label": "%[1]s, %[2]s",
+	"cell.hit":   "Treffer",
+	"cell.miss":  "Wasser",
+	"cell.ship":  "Schiff",
+
+	"announce.you":      "Du hast auf %[1]s geschossen: %[2]s",
+	"announce.opponent": "Dein Gegner hat auf %[1]s geschossen: %[2]s",
+	"announce.player":   "%[1]s hat auf %[2]s geschossen: %[3]s",
+	"announce.end":      "%[1]s. %[2]s",
+
+	"game.keyboard": "Bewege dich mit den Pfeiltasten über die Spielfelder und drücke Enter, um zu schießen",
+
+	"place.title":       "Platziere deine Schiffe",
+	"place.help":        "Wähle ein Schiff, gehe mit den Pfeiltasten zum Feld für sein oberes linkes Ende und drücke Enter. Drücke R, um es zu drehen.",
+	"place.ships":       "Schiffe",
+	"place.ship.one":    "%[2]s, %[1]d Feld",
+	"place.ship.other":  "%[2]s, %[1]d Felder",
+	"place.orientation": "Richtung",
+	"place.horizontal":  "Waagerecht",
+	"place.vertical":    "Senkrecht",
+	"place.placed":      "%[1]s auf %[2]s platziert, %[3]s",
+	"place.done":        "Alle Schiffe platziert",
+
+	"ship.1": "Flugzeugträger",
+	"ship.2": "Schlachtschiff",
+	"ship.3": "Kreuzer",
+	"ship.4": "U-Boot",
+	"ship.5": "Zerstörer",
+
+	"coordinate.columns": "ABCDEFGHIJ",
+	"coordinate":         "%[1]s%[2]d",
+}
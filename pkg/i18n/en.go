@@ -0,0 +1,195 @@
+package i18n
+
+// English, which every other catalog translates. Keys ending in .one and
+// .other are the plural forms of a message, see Locale.N.
+var en = map[string]string{
+	"site.title":       "Battleships",
+	"site.description": "Sink your opponent's fleet before they sink yours",
+	"site.language":    "Language",
+
+	"index.new_game":    "New game",
+	"index.play_online": "Play online",
+	"index.watch":       "Watch live games",
+	"index.history":     "Finished games",
+	"index.leaderboard": "Leaderboard",
+
+	"account.register":         "Register",
+	"account.register_note":    "Games you have played as a guest in this browser are kept",
+	"account.login":            "Log in",
+	"account.no_account":       "No account? Register",
+	"account.anonymous":        "%[1]s or %[2]s",
+	"account.register_link":    "register",
+	"account.guest":            "Playing as %[1]s. %[2]s to keep your games, or %[3]s",
+	"account.login_link":       "log in",
+	"account.logged_in":        "Logged in as %[1]s.",
+	"account.logout":           "Log out",
+	"account.name":             "Name",
+	"account.password":         "Password",
+	"account.bot":              " (bot)",
+	"account.registered":       "You are already registered, log out first",
+	"account.name_taken":       "That name is taken",
+	"account.invalid_name":     "names are 3 to 20 letters, numbers, - or _",
+	"account.invalid_password": "passwords are %[1]d to %[2]d characters",
+	"account.wrong_password":   "wrong name or password",
+
+	"chat.title":         "Chat",
+	"chat.placeholder":   "Say something",
+	"chat.send":          "Send",
+	"chat.mute":          "Mute",
+	"chat.unmute":        "Unmute",
+	"chat.report":        "Report",
+	"chat.reported":      "Reported, thank you",
+	"chat.muted":         "%[1]s is muted",
+	"chat.empty":         "No messages yet",
+	"chat.empty_message": "message is empty",
+	"chat.too_long":      "messages are at most %[1]d characters",
+	"chat.too_fast":      "sending messages too quickly, wait a moment",
+	"chat.closed":        "chat has closed",
+
+	"error.request_id":       "Request ID: %[1]s",
+	"error.home":             "Back to the home page",
+	"error.server":           "Sorry, something went wrong. Please try again.",
+	"error.not_seated":       "You are not seated in this game",
+	"error.invalid_token":    "That resume link is not valid",
+	"error.banned":           "too many invalid moves, try again later",
+	"error.rate_limited":     "too many requests, slow down",
+	"error.invalid_request":  "invalid request body",
+	"error.unauthorized":     "missing or invalid seat token",
+	"error.game_not_found":   "game not found",
+	"error.game_full":        "game is full",
+	"error.game_in_progress": "game is still in progress",
+	"error.wrong_phase":      "action not allowed in this phase",
+	"error.suspended":        "game is suspended while the server restarts",
+	"error.player_not_found": "player not found",
+	"error.coordinates":      "coordinates must be numbers",
+	"error.coordinate":       "that square is not on the board",
+	"error.off_board":        "that would go off the board",
+	"error.occupied":         "ships can't overlap",
+	"error.invalid_fleet":    "invalid fleet",
+	"error.already_ready":    "fleet already locked in",
+	"error.fleet_not_ready":  "fleet has not been placed",
+	"error.not_your_turn":    "not your turn",
+	"error.already_fired":    "coordinate already fired at",
+	"error.form":             "invalid form",
+	"error.choose_ship":      "choose a ship to place",
+	"error.orientation":      "choose which way the ship faces",
+	"error.placement":        "invalid ship placement",
+	"error.ship_fit":         "ship doesn't fit there",
+	"error.ship_id":          "ship id must be a number",
+	"error.ship_unavailable": "ship id unavailable",
+	"error.move":             "move must be a number",
+	"error.delay":            "spectator delay must be a duration like 30s",
+	"error.delay_range":      "spectator delay out of range",
+
+	"http.400": "Bad Request",
+	"http.403": "Forbidden",
+	"http.404": "Not Found",
+	"http.409": "Conflict",
+	"http.429": "Too Many Requests",
+	"http.500": "Internal Server Error",
+	"http.503": "Service Unavailable",
+
+	"game.resume":       "Resume link: ",
+	"game.waiting_for":  "%[1]s is waiting for an opponent",
+	"game.join":         "Join game",
+	"game.share":        "Share this link with your opponent: ",
+	"game.restarting":   "The server is restarting, the game will carry on shortly",
+	"game.your_fleet":   "Your fleet",
+	"game.enemy_waters": "Enemy waters",
+	"game.fleet":        "%[1]s's fleet",
+	"game.shuffle":      "Shuffle",
+	"game.ready":        "Ready",
+	"game.replay":       "Watch the replay",
+	"game.waiting":      "Waiting",
+	"game.to_fire":      " (%[1]s to fire)",
+	"game.reconnecting": "Waiting for opponent to reconnect",
+	"game.forfeit_in":   "Waiting for opponent to reconnect, they forfeit in %[1]s",
+
+	"status.no_opponent":  "Waiting for an opponent",
+	"status.placing_wait": "Waiting for your opponent to place their fleet",
+	"status.placing":      "Place your fleet",
+	"status.won_forfeit":  "You won! Your opponent forfeited",
+	"status.won":          "You won!",
+	"status.lost_forfeit": "You lost by forfeit",
+	"status.lost":         "You lost",
+	"status.your_turn":    "Your turn",
+	"status.their_turn":   "Opponent's turn",
+
+	"spectate.delayed": "Delayed by %[1]s",
+	"spectate.placing": "Players are placing their fleets",
+	"spectate.won":     "%[1]s won!",
+	"spectate.to_fire": "%[1]s to fire",
+
+	"queue.looking": "Looking for an opponent",
+	"queue.found":   "Opponent found",
+
+	"watch.title":      "Live games",
+	"watch.empty":      "No games in progress",
+	"watch.game.one":   "%[2]s vs %[3]s (%[1]d shot)",
+	"watch.game.other": "%[2]s vs %[3]s (%[1]d shots)",
+
+	"history.title":        "Finished games",
+	"history.empty":        "No games have finished yet",
+	"history.game":         "%[1]s vs %[2]s",
+	"history.result.one":   ", %[2]s won in %[1]d move",
+	"history.result.other": ", %[2]s won in %[1]d moves",
+
+	"replay.first":       "First",
+	"replay.previous":    "Previous",
+	"replay.pause":       "Pause",
+	"replay.play":        "Play",
+	"replay.next":        "Next",
+	"replay.last":        "Last",
+	"replay.move":        "Move %[1]d of %[2]d: %[3]s fired at %[4]s and %[5]s",
+	"replay.hit":         "hit",
+	"replay.missed":      "missed",
+	"replay.won":         ". %[1]s won",
+	"replay.won_forfeit": ". %[1]s won by forfeit",
+
+	"player.no_games":     "No finished games yet",
+	"player.played":       "Games played",
+	"player.won_lost":     "Won / lost",
+	"player.accuracy":     "Accuracy",
+	"player.shots_to_win": "Shots to win",
+	"player.openings":     "Favourite openings",
+	"player.rating":       "Rating %[1]s",
+	"player.placements":   "Where they place ships",
+	"player.fired":        "Where they fire",
+
+	"leaderboard.title":  "Leaderboard",
+	"leaderboard.empty":  "Nobody has finished a rated game yet",
+	"leaderboard.player": "Player",
+	"leaderboard.rating": "Rating",
+
+	"cell.label": "%[1]s, %[2]s",
+	"cell.hit":   "hit",
+	"cell.miss":  "miss",
+	"cell.ship":  "ship",
+
+	"announce.you":      "You fired at %[1]s: %[2]s",
+	"announce.opponent": "Your opponent fired at %[1]s: %[2]s",
+	"announce.player":   "%[1]s fired at %[2]s: %[3]s",
+	"announce.end":      "%[1]s. %[2]s",
+
+	"game.keyboard": "Move around the boards with the arrow keys and press Enter to fire",
+
+	"place.title":       "Place your ships",
+	"place.help":        "Choose a ship, then move to the square for its top left end with the arrow keys and press Enter. Press R to turn it.",
+	"place.ships":       "Ships",
+	"place.ship.one":    "%[2]s, %[1]d square",
+	"place.ship.other":  "%[2]s, %[1]d squares",
+	"place.orientation": "Direction",
+	"place.horizontal":  "Across",
+	"place.vertical":    "Down",
+	"place.placed":      "%[1]s placed at %[2]s, %[3]s",
+	"place.done":        "All ships placed",
+
+	"ship.1": "Carrier",
+	"ship.2": "Battleship",
+	"ship.3": "Cruiser",
+	"ship.4": "Submarine",
+	"ship.5": "Destroyer",
+
+	"coordinate.columns": "ABCDEFGHIJ",
+	"coordinate":         "%[1]s%[2]d",
+}
@@ -0,0 +1,193 @@
+package i18n
+
+var es = map[string]string{
+	"site.title":       "Hundir la flota",
+	"site.description": "Hunde la flota de tu rival antes de que hunda la tuya",
+	"site.language":    "Idioma",
+
+	"index.new_game":    "Nueva partida",
+	"index.play_online": "Jugar en línea",
+	"index.watch":       "Ver partidas en directo",
+	"index.history":     "Partidas terminadas",
+	"index.leaderboard": "Clasificación",
+
+	"account.register":         "Registrarse",
+	"account.register_note":    "Las partidas que has jugado como invitado en este navegador se conservan",
+	"account.login":            "Iniciar sesión",
+	"account.no_account":       "¿No tienes cuenta? Regístrate",
+	"account.anonymous":        "%[1]s o %[2]s",
+	"account.register_link":    "regístrate",
+	"account.guest":            "Juegas como %[1]s. %[2]s para conservar tus partidas, o %[3]s",
+	"account.login_link":       "inicia sesión",
+	"account.logged_in":        "Sesión iniciada como %[1]s.",
+	"account.logout":           "Cerrar sesión",
+	"account.name":             "Nombre",
+	"account.password":         "Contraseña",
+	"account.bot":              " (bot)",
+	"account.registered":       "Ya estás registrado, cierra la sesión primero",
+	"account.name_taken":       "Ese nombre ya está en uso",
+	"account.invalid_name":     "los nombres tienen de 3 a 20 letras, números, - o _",
+	"account.invalid_password": "las contraseñas tienen de %[1]d a %[2]d caracteres",
+	"account.wrong_password":   "nombre o contraseña incorrectos",
+
+	"chat.title":         "Chat",
+	"chat.placeholder":   "Escribe algo",
+	"chat.send":          "Enviar",
+	"chat.mute":          "Silenciar",
+	"chat.unmute":        "Dejar de silenciar",
+	"chat.report":        "Denunciar",
+	"chat.reported":      "Denunciado, gracias",
+	"chat.muted":         "%[1]s está silenciado",
+	"chat.empty":         "Todavía no hay mensajes",
+	"chat.empty_message": "el mensaje está vacío",
+	"chat.too_long":      "los mensajes tienen como máximo %[1]d caracteres",
+	"chat.too_fast":      "envías mensajes demasiado rápido, espera un momento",
+	"chat.closed":        "el chat está cerrado",
+
+	"error.request_id":       "ID de la petición: %[1]s",
+	"error.home":             "Volver a la página de inicio",
+	"error.server":           "Lo sentimos, algo ha ido mal. Inténtalo de nuevo.",
+	"error.not_seated":       "No tienes asiento en esta partida",
+	"error.invalid_token":    "Ese enlace para continuar no es válido",
+	"error.banned":           "demasiados movimientos no válidos, inténtalo más tarde",
+	"error.rate_limited":     "demasiadas peticiones, ve más despacio",
+	"error.invalid_request":  "cuerpo de la petición no válido",
+	"error.unauthorized":     "falta el token del asiento o no es válido",
+	"error.game_not_found":   "partida no encontrada",
+	"error.game_full":        "la partida está completa",
+	"error.game_in_progress": "la partida sigue en curso",
+	"error.wrong_phase":      "no se puede hacer eso en esta fase",
+	"error.suspended":        "la partida está en pausa mientras se reinicia el servidor",
+	"error.player_not_found": "jugador no encontrado",
+	"error.coordinates":      "las coordenadas deben ser números",
+	"error.coordinate":       "esa casilla no está en el tablero",
+	"error.off_board":        "se saldría del tablero",
+	"error.occupied":         "los barcos no pueden solaparse",
+	"error.invalid_fleet":    "flota no válida",
+	"error.already_ready":    "la flota ya está confirmada",
+	"error.fleet_not_ready":  "la flota no se ha colocado",
+	"error.not_your_turn":    "no es tu turno",
+	"error.already_fired":    "ya se ha disparado a esa coordenada",
+	"error.form":             "formulario no válido",
+	"error.choose_ship":      "elige un barco para colocar",
+	"error.orientation":      "elige hacia dónde mira el barco",
+	"error.placement":        "colocación de barco no válida",
+	"error.ship_fit":         "el barco no cabe ahí",
+	"error.ship_id":          "el id del barco debe ser un número",
+	"error.ship_unavailable": "id de barco no disponible",
+	"error.move":             "el movimiento debe ser un número",
+	"error.delay":            "el retraso debe ser una duración como 30s",
+	"error.delay_range":      "retraso fuera de rango",
+
+	"http.400": "Petición incorrecta",
+	"http.403": "Prohibido",
+	"http.404": "No encontrado",
+	"http.409": "Conflicto",
+	"http.429": "Demasiadas peticiones",
+	"http.500": "Error interno del servidor",
+	"http.503": "Servicio no disponible",
+
+	"game.resume":       "Enlace para continuar: ",
+	"game.waiting_for":  "%[1]s está esperando un rival",
+	"game.join":         "Unirse a la partida",
+	"game.share":        "Comparte este enlace con tu rival: ",
+	"game.restarting":   "El servidor se está reiniciando, la partida continuará en breve",
+	"game.your_fleet":   "Tu flota",
+	"game.enemy_waters": "Aguas enemigas",
+	"game.fleet":        "Flota de %[1]s",
+	"game.shuffle":      "Barajar",
+	"game.ready":        "Listo",
+	"game.replay":       "Ver la repetición",
+	"game.waiting":      "Esperando",
+	"game.to_fire":      " (%[1]s para disparar)",
+	"game.reconnecting": "Esperando a que tu rival se reconecte",
+	"game.forfeit_in":   "Esperando a que tu rival se reconecte, pierde por abandono en %[1]s",
+
+	"status.no_opponent":  "Esperando un rival",
+	"status.placing_wait": "Esperando a que tu rival coloque su flota",
+	"status.placing":      "Coloca tu flota",
+	"status.won_forfeit":  "¡Has ganado! Tu rival ha abandonado",
+	"status.won":          "¡Has ganado!",
+	"status.lost_forfeit": "Has perdido por abandono",
+	"status.lost":         "Has perdido",
+	"status.your_turn":    "Tu turno",
+	"status.their_turn":   "Turno del rival",
+
+	"spectate.delayed": "Con un retraso de %[1]s",
+	"spectate.placing": "Los jugadores están colocando sus flotas",
+	"spectate.won":     "¡%[1]s ha ganado!",
+	"spectate.to_fire": "Dispara %[1]s",
+
+	"queue.looking": "Buscando un rival",
+	"queue.found":   "Rival encontrado",
+
+	"watch.title":      "Partidas en directo",
+	"watch.empty":      "No hay partidas en curso",
+	"watch.game.one":   "%[2]s contra %[3]s (%[1]d disparo)",
+	"watch.game.other": "%[2]s contra %[3]s (%[1]d disparos)",
+
+	"history.title":        "Partidas terminadas",
+	"history.empty":        "Todavía no ha terminado ninguna partida",
+	"history.game":         "%[1]s contra %[2]s",
+	"history.result.one":   ", %[2]s ganó en %[1]d jugada",
+	"history.result.other": ", %[2]s ganó en %[1]d jugadas",
+
+	"replay.first":       "Primera",
+	"replay.previous":    "Anterior",
+	"replay.pause":       "Pausa",
+	"replay.play":        "Reproducir",
+	"replay.next":        "Siguiente",
+	"replay.last":        "Última",
+	"replay.move":        "Jugada %[1]d de %[2]d: %[3]s disparó a %[4]s y %[5]s",
+	"replay.hit":         "acertó",
+	"replay.missed":      "falló",
+	"replay.won":         ". %[1]s ganó",
+	"replay.won_forfeit": ". %[1]s ganó por abandono",
+
+	"player.no_games":     "Todavía no hay partidas terminadas",
+	"player.played":       "Partidas jugadas",
+	"player.won_lost":     "Ganadas / perdidas",
+	"player.accuracy":     "Precisión",
+	"player.shots_to_win": "Disparos para ganar",
+	"player.openings":     "Aperturas favoritas",
+	"player.rating":       "Puntuación %[1]s",
+	"player.placements":   "Dónde coloca sus barcos",
+	"player.fired":        "Dónde dispara",
+
+	"leaderboard.title":  "Clasificación",
+	"leaderboard.empty":  "Nadie ha terminado todavía una partida puntuada",
+	"leaderboard.player": "Jugador",
+	"leaderboard.rating": "Puntuación",
+
+	"cell.label": "%[1]s, %[2]s",
+	"cell.hit":   "tocado",
+	"cell.miss":  "agua",
+	"cell.ship":  "barco",
+
+	"announce.you":      "Has disparado a %[1]s: %[2]s",
+	"announce.opponent": "Tu rival ha disparado a %[1]s: %[2]s",
+	"announce.player":   "%[1]s ha disparado a %[2]s: %[3]s",
+	"announce.end":      "%[1]s. %[2]s",
+
+	"game.keyboard": "Muévete por los tableros con las flechas y pulsa Intro para disparar",
+
+	"place.title":       "Coloca tus barcos",
+	"place.help":        "Elige un barco, ve con las flechas a la casilla de su extremo superior izquierdo y pulsa Intro. Pulsa R para girarlo.",
+	"place.ships":       "Barcos",
+	"place.ship.one":    "%[2]s, %[1]d casilla",
+	"place.ship.other":  "%[2]s, %[1]d casillas",
+	"place.orientation": "Dirección",
+	"place.horizontal":  "Horizontal",
+	"place.vertical":    "Vertical",
+	"place.placed":      "%[1]s colocado en %[2]s, %[3]s",
+	"place.done":        "Todos los barcos colocados",
+
+	"ship.1": "Portaaviones",
+	"ship.2": "Acorazado",
+	"ship.3": "Crucero",
+	"ship.4": "Submarino",
+	"ship.5": "Destructor",
+
+	"coordinate.columns": "ABCDEFGHIJ",
+	"coordinate":         "%[1]s%[2]d",
+}
@@ -0,0 +1,153 @@
+// Package i18n translates the site's text. Messages are looked up by key
+// in a catalog per language and formatted like fmt.Sprintf, with indexed
+// verbs such as %[2]s so translations can reorder their arguments.
+package i18n
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/alfiehiscox/submarines/pkg/cell"
+	"golang.org/x/text/language"
+)
+
+// Plural forms a message can have, suffixed to its key
+const (
+	ONE   = "one"
+	OTHER = "other"
+)
+
+// A language's messages, and how it picks between their plural forms.
+type Catalog struct {
+	Tag language.Tag
+
+	// What the language calls itself, for choosing between them
+	Name string
+
+	Plural   func(n int) string
+	Messages map[string]string
+}
+
+// Messages and plural rules for English, Spanish and German, all of
+// which use one form for exactly one and another for everything else.
+var (
+	ENGLISH = &Catalog{Tag: language.English, Name: "English", Plural: oneOther, Messages: en}
+	SPANISH = &Catalog{Tag: language.Spanish, Name: "Español", Plural: oneOther, Messages: es}
+	GERMAN  = &Catalog{Tag: language.German, Name: "Deutsch", Plural: oneOther, Messages: de}
+
+	// The first is used when nothing else matches
+	CATALOGS = []*Catalog{ENGLISH, SPANISH, GERMAN}
+)
+
+var matcher = language.NewMatcher(tags())
+
+// A language to show text in. The zero value is English.
+type Locale struct {
+	catalog *Catalog
+}
+
+// Returns the best locale for a user who chose override, if anything,
+// and whose browser sent accept as its Accept-Language header.
+func Negotiate(override, accept string) Locale {
+	_, i := language.MatchStrings(matcher, override, accept)
+	return Locale{CATALOGS[i]}
+}
+
+// Returns the locale for tag, if there is a catalog for it.
+func Lookup(tag string) (Locale, bool) {
+	for _, c := range CATALOGS {
+		if c.Tag.String() == tag {
+			return Locale{c}, true
+		}
+	}
+	return Locale{}, false
+}
+
+// Returns every locale there is a catalog for.
+func Locales() []Locale {
+	locales := make([]Locale, len(CATALOGS))
+	for i, c := range CATALOGS {
+		locales[i] = Locale{c}
+	}
+	return locales
+}
+
+func (l Locale) Catalog() *Catalog {
+	if l.catalog == nil {
+		return ENGLISH
+	}
+	return l.catalog
+}
+
+// The locale's BCP 47 tag, such as "es".
+func (l Locale) Tag() string {
+	return l.Catalog().Tag.String()
+}
+
+func (l Locale) Name() string {
+	return l.Catalog().Name
+}
+
+// Returns the message for key formatted with args. Keys missing from the
+// locale's catalog fall back to English, then to the key itself. Without
+// args the message is returned as it is, verbs and all.
+func (l Locale) T(key string, args ...any) string {
+	message, ok := l.Catalog().Messages[key]
+	if !ok {
+		if message, ok = en[key]; !ok {
+			return key
+		}
+	}
+
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// Returns the plural form of the message for key that suits n, formatted
+// with n followed by args.
+func (l Locale) N(key string, n int, args ...any) string {
+	return l.T(key+"."+l.Catalog().Plural(n), append([]any{n}, args...)...)
+}
+
+// Returns the name of the ship with id, numbered from 1 for the carrier
+// to 5 for the destroyer as in the ship gallery.
+func (l Locale) Ship(id int) string {
+	return l.T(fmt.Sprintf("ship.%d", id))
+}
+
+// Labels c the way players call out shots, such as "B7": a letter for
+// the column and a number for the row, both counting from one.
+func (l Locale) Coordinate(c cell.Coordinate) string {
+	columns := []rune(l.T("coordinate.columns"))
+	column := fmt.Sprint(c[0] + 1)
+	if c[0] >= 0 && c[0] < len(columns) {
+		column = string(columns[c[0]])
+	}
+	return l.T("coordinate", column, c[1]+1)
+}
+
+// Returns the name of an HTTP status, for error pages.
+func (l Locale) Status(code int) string {
+	key := fmt.Sprintf("http.%d", code)
+	if _, ok := en[key]; ok {
+		return l.T(key)
+	}
+	return http.StatusText(code)
+}
+
+func oneOther(n int) string {
+	if n == 1 {
+		return ONE
+	}
+	return OTHER
+}
+
+func tags() []language.Tag {
+	tags := make([]language.Tag, len(CATALOGS))
+	for i, c := range CATALOGS {
+		tags[i] = c.Tag
+	}
+	return tags
+}
@@ -0,0 +1,81 @@
+package i18n
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/alfiehiscox/submarines/pkg/cell"
+)
+
+var verb = regexp.MustCompile(`%(\[\d+\])?[a-z]`)
+
+func TestCatalogsComplete(t *testing.T) {
+	for _, c := range CATALOGS {
+		for key, message := range en {
+			translated, ok := c.Messages[key]
+			if !ok {
+				t.Fatalf("%s: missing %q", c.Tag, key)
+			}
+
+			want := verb.FindAllString(message, -1)
+			got := verb.FindAllString(translated, -1)
+			slices.Sort(want)
+			slices.Sort(got)
+			if !slices.Equal(want, got) {
+				t.Fatalf("%s: expected %q to use %v, got %v", c.Tag, key, want, got)
+			}
+		}
+
+		for key := range c.Messages {
+			if _, ok := en[key]; !ok {
+				t.Fatalf("%s: %q isn't in the English catalog", c.Tag, key)
+			}
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	for _, tc := range []struct {
+		override, accept string
+		want             string
+	}{
+		{"", "", "en"},
+		{"", "es-MX,es;q=0.9,en;q=0.8", "es"},
+		{"", "fr-FR,de;q=0.7", "de"},
+		{"", "ja", "en"},
+		{"de", "es", "de"},
+		{"xx", "es", "es"},
+	} {
+		if got := Negotiate(tc.override, tc.accept).Tag(); got != tc.want {
+			t.Fatalf("expected %q and %q to give %s, got %s", tc.override, tc.accept, tc.want, got)
+		}
+	}
+}
+
+func TestLocale(t *testing.T) {
+	var zero Locale
+	if got := zero.T("site.title"); got != "Battleships" {
+		t.Fatalf("expected the zero locale to be English, got %q", got)
+	}
+
+	es, _ := Lookup("es")
+	de, _ := Lookup("de")
+
+	for _, tc := range []struct {
+		got, want string
+	}{
+		{es.N("watch.game", 1, "Ana", "Luis"), "Ana contra Luis (1 disparo)"},
+		{es.N("watch.game", 3, "Ana", "Luis"), "Ana contra Luis (3 disparos)"},
+		{de.N("history.result", 0, "Jan"), ", Jan gewann in 0 Zügen"},
+		{de.Ship(4), "U-Boot"},
+		{es.Coordinate(cell.Coordinate{1, 6}), "B7"},
+		{de.Status(404), "Nicht gefunden"},
+		{de.Status(418), "I'm a teapot"},
+		{es.T("no.such.key"), "no.such.key"},
+	} {
+		if tc.got != tc.want {
+			t.Fatalf("expected %q, got %q", tc.want, tc.got)
+		}
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/alfiehiscox/submarines/pkg/cell"
 )
 
+var ErrOccupied = errors.New("already occupied")
+
 type Player struct {
 	Name string
 
@@ -39,16 +41,14 @@ func (p *Player) place_ship(size int, orientation cell.Orientation, coord cell.C
 		for i := 0; i < size; i++ {
 			cell := p.PlayerBoard[idx+i]
 			if cell.Occupied {
-				msg := fmt.Sprintf("Cell at %v already occupied", coord)
-				return errors.New(msg)
+				return fmt.Errorf("Cell at %v %w", coord, ErrOccupied)
 			}
 		}
 	case cell.VERTICAL:
 		for i := 0; i < size; i++ {
 			cell := p.PlayerBoard[idx+(i*cell.BOARD_WIDTH)]
 			if cell.Occupied {
-				msg := fmt.Sprintf("Cell at %v already occupied", coord)
-				return errors.New(msg)
+				return fmt.Errorf("Cell at %v %w", coord, ErrOccupied)
 			}
 		}
 	default:
@@ -70,6 +70,11 @@ func (p *Player) place_ship(size int, orientation cell.Orientation, coord cell.C
 	return nil
 }
 
+// Places a ship of size on player_board. Errors if invalid placement.
+func (p *Player) PlaceShip(size int, orientation cell.Orientation, coord cell.Coordinate) error {
+	return p.place_ship(size, orientation, coord)
+}
+
 func (p *Player) RandomizeShipPlacement(size int) error {
 
 	orientation := cell.GetRandomOrientation()
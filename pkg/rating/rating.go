@@ -0,0 +1,143 @@
+// Package rating implements the Glicko-2 rating system, as described in
+// Glickman's "Example of the Glicko-2 system". Every rated game is its own
+// rating period.
+package rating
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	INITIAL_RATING     = 1500
+	INITIAL_DEVIATION  = 350
+	INITIAL_VOLATILITY = 0.06
+
+	// Constrains how quickly volatility changes, 0.3 to 1.2 is sensible
+	TAU = 0.5
+
+	// Converts between the Glicko and Glicko-2 scales
+	scale     = 173.7178
+	tolerance = 0.000001
+)
+
+// A player's strength: Rating is the estimate, Deviation how unsure it is
+// and Volatility how erratic the player's results are.
+type Rating struct {
+	Rating     float64
+	Deviation  float64
+	Volatility float64
+}
+
+// The outcome of one game against Opponent. Score is 1 for a win, 0 for
+// a loss.
+type Result struct {
+	Opponent Rating
+	Score    float64
+}
+
+// Change is an account's rating after a game.
+type Change struct {
+	Account string
+	Game    string
+	At      time.Time
+	Rating  Rating
+}
+
+// Returns the rating of someone who has never played a rated game.
+func Default() Rating {
+	return Rating{Rating: INITIAL_RATING, Deviation: INITIAL_DEVIATION, Volatility: INITIAL_VOLATILITY}
+}
+
+// Returns r after a rating period with results. With no results only the
+// deviation grows.
+func Update(r Rating, results []Result) Rating {
+	mu := (r.Rating - INITIAL_RATING) / scale
+	phi := r.Deviation / scale
+	sigma := r.Volatility
+
+	if len(results) == 0 {
+		phi = math.Min(math.Sqrt(phi*phi+sigma*sigma), INITIAL_DEVIATION/scale)
+		return Rating{Rating: r.Rating, Deviation: phi * scale, Volatility: sigma}
+	}
+
+	// Estimated variance and improvement from the results
+	var inverseV, sum float64
+	for _, result := range results {
+		muJ := (result.Opponent.Rating - INITIAL_RATING) / scale
+		gJ := g(result.Opponent.Deviation / scale)
+		e := expected(mu, muJ, gJ)
+
+		inverseV += gJ * gJ * e * (1 - e)
+		sum += gJ * (result.Score - e)
+	}
+	v := 1 / inverseV
+	delta := v * sum
+
+	sigma = volatility(phi, sigma, v, delta)
+
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	phi = 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	mu += phi * phi * sum
+
+	return Rating{
+		Rating:     mu*scale + INITIAL_RATING,
+		Deviation:  math.Min(phi*scale, INITIAL_DEVIATION),
+		Volatility: sigma,
+	}
+}
+
+// Returns the new ratings of a and b after a game between them.
+func Game(a, b Rating, aWon bool) (Rating, Rating) {
+	score := 0.0
+	if aWon {
+		score = 1
+	}
+
+	return Update(a, []Result{{Opponent: b, Score: score}}),
+		Update(b, []Result{{Opponent: a, Score: 1 - score}})
+}
+
+func g(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+func expected(mu, muJ, gJ float64) float64 {
+	return 1 / (1 + math.Exp(-gJ*(mu-muJ)))
+}
+
+// Finds the new volatility with the Illinois algorithm (step 5).
+func volatility(phi, sigma, v, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-phi*phi-v-ex)/(2*d*d) - (x-a)/(TAU*TAU)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*TAU) < 0 {
+			k++
+		}
+		B = a - k*TAU
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > tolerance {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+
+	return math.Exp(A / 2)
+}
@@ -0,0 +1,48 @@
+package rating
+
+import (
+	"math"
+	"testing"
+)
+
+// The worked example from Glickman's paper
+func TestUpdate(t *testing.T) {
+	player := Rating{Rating: 1500, Deviation: 200, Volatility: 0.06}
+	results := []Result{
+		{Opponent: Rating{Rating: 1400, Deviation: 30}, Score: 1},
+		{Opponent: Rating{Rating: 1550, Deviation: 100}, Score: 0},
+		{Opponent: Rating{Rating: 1700, Deviation: 300}, Score: 0},
+	}
+
+	got := Update(player, results)
+	want := Rating{Rating: 1464.06, Deviation: 151.52, Volatility: 0.05999}
+
+	if math.Abs(got.Rating-want.Rating) > 0.01 ||
+		math.Abs(got.Deviation-want.Deviation) > 0.01 ||
+		math.Abs(got.Volatility-want.Volatility) > 0.00001 {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestGame(t *testing.T) {
+	winner, loser := Game(Default(), Default(), true)
+
+	if winner.Rating <= INITIAL_RATING || loser.Rating >= INITIAL_RATING {
+		t.Fatalf("expected the winner to gain and the loser to lose, got %v and %v", winner.Rating, loser.Rating)
+	}
+
+	if winner.Deviation >= INITIAL_DEVIATION || loser.Deviation >= INITIAL_DEVIATION {
+		t.Fatal("expected playing to make ratings more certain")
+	}
+}
+
+func TestNoGamesGrowsDeviation(t *testing.T) {
+	r := Rating{Rating: 1600, Deviation: 50, Volatility: 0.06}
+	if got := Update(r, nil); got.Rating != r.Rating || got.Deviation <= r.Deviation {
+		t.Fatalf("expected only the deviation to grow, got %+v", got)
+	}
+
+	if got := Update(Default(), nil); got.Deviation != INITIAL_DEVIATION {
+		t.Fatalf("expected deviation to be capped, got %v", got.Deviation)
+	}
+}
@@ -0,0 +1,130 @@
+// Package stats summarises how an account plays from its stored games.
+package stats
+
+import (
+	"sort"
+
+	"github.com/alfiehiscox/submarines/pkg/cell"
+	"github.com/alfiehiscox/submarines/pkg/game"
+)
+
+const (
+	CELLS = cell.BOARD_WIDTH * cell.BOARD_HEIGHT
+
+	// Favourite opening shots kept
+	OPENINGS = 3
+)
+
+type Stats struct {
+	// Finished games only
+	Played int
+	Wins   int
+	Losses int
+
+	Shots int
+	Hits  int
+
+	// Shots fired in games won, to average over Wins
+	winningShots int
+
+	// Most common first shots, most common first
+	Openings []Opening
+
+	// How many games a ship covered each cell, by board index
+	Placements [CELLS]int
+
+	// How many shots were fired at each cell, by board index
+	Fired [CELLS]int
+}
+
+type Opening struct {
+	Coordinate cell.Coordinate
+	Count      int
+}
+
+// Returns the fraction of shots that hit, 0 before any are fired.
+func (s Stats) Accuracy() float64 {
+	if s.Shots == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Shots)
+}
+
+// Returns the average number of shots fired in games won, 0 without any
+// wins.
+func (s Stats) ShotsToWin() float64 {
+	if s.Wins == 0 {
+		return 0
+	}
+	return float64(s.winningShots) / float64(s.Wins)
+}
+
+// Works out the stats of the account from the games it has played.
+// Games without a seat for the account are ignored.
+func Compute(account string, records []game.Record) Stats {
+	var s Stats
+	openings := make(map[cell.Coordinate]int)
+
+	for _, record := range records {
+		seat := -1
+		for i, seated := range record.Seats {
+			if account != "" && seated.Account == account {
+				seat = i
+			}
+		}
+
+		if seat < 0 || record.Phase != game.FINISHED {
+			continue
+		}
+
+		s.Played++
+		won := record.Winner == seat
+		if won {
+			s.Wins++
+		} else {
+			s.Losses++
+		}
+
+		for _, coord := range record.Seats[seat].Fleet {
+			s.Placements[coord.ToIndex()]++
+		}
+
+		first := true
+		for _, move := range record.Moves {
+			if move.Seat != seat {
+				continue
+			}
+
+			if first {
+				openings[move.Coordinate]++
+				first = false
+			}
+
+			s.Shots++
+			s.Fired[move.Coordinate.ToIndex()]++
+			if move.Hit {
+				s.Hits++
+			}
+			if won {
+				s.winningShots++
+			}
+		}
+	}
+
+	for coord, count := range openings {
+		s.Openings = append(s.Openings, Opening{Coordinate: coord, Count: count})
+	}
+
+	// Ties go to the cell nearest the top left, so the order is stable
+	sort.Slice(s.Openings, func(i, j int) bool {
+		if s.Openings[i].Count != s.Openings[j].Count {
+			return s.Openings[i].Count > s.Openings[j].Count
+		}
+		return s.Openings[i].Coordinate.ToIndex() < s.Openings[j].Coordinate.ToIndex()
+	})
+	if len(s.Openings) > OPENINGS {
+		s.Openings = s.Openings[:OPENINGS]
+	}
+
+	return s
+}
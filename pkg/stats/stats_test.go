@@ -0,0 +1,53 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/alfiehiscox/submarines/pkg/cell"
+	"github.com/alfiehiscox/submarines/pkg/game"
+)
+
+func record(winner int, moves ...game.Move) game.Record {
+	r := game.Record{Phase: game.FINISHED, Winner: winner, Moves: moves}
+	r.Seats[0] = game.SeatRecord{Account: "me", Fleet: []cell.Coordinate{{0, 0}, {1, 0}}}
+	r.Seats[1] = game.SeatRecord{Account: "them", Fleet: []cell.Coordinate{{5, 5}}}
+	return r
+}
+
+func TestCompute(t *testing.T) {
+	records := []game.Record{
+		record(0,
+			game.Move{Seat: 0, Coordinate: cell.Coordinate{5, 5}, Hit: true},
+			game.Move{Seat: 1, Coordinate: cell.Coordinate{9, 9}},
+		),
+		record(1,
+			game.Move{Seat: 0, Coordinate: cell.Coordinate{5, 5}},
+			game.Move{Seat: 1, Coordinate: cell.Coordinate{0, 0}, Hit: true},
+			game.Move{Seat: 0, Coordinate: cell.Coordinate{4, 4}},
+		),
+		// Unfinished games don't count
+		{Phase: game.PLAYING, Seats: [game.SEATS]game.SeatRecord{{Account: "me"}}},
+	}
+
+	s := Compute("me", records)
+
+	if s.Played != 2 || s.Wins != 1 || s.Losses != 1 {
+		t.Fatalf("expected 1 win and 1 loss, got %+v", s)
+	}
+
+	if s.Shots != 3 || s.Accuracy() != 1.0/3 || s.ShotsToWin() != 1 {
+		t.Fatalf("expected 3 shots, 1 hit and 1 shot to win, got %+v", s)
+	}
+
+	if len(s.Openings) != 1 || s.Openings[0] != (Opening{cell.Coordinate{5, 5}, 2}) {
+		t.Fatalf("expected (5, 5) to be the favourite opening, got %v", s.Openings)
+	}
+
+	if s.Placements[0] != 2 || s.Placements[55] != 0 || s.Fired[55] != 2 || s.Fired[99] != 0 {
+		t.Fatal("expected heatmaps of the account's own ships and shots")
+	}
+
+	if empty := Compute("nobody", records); empty.Played != 0 || empty.Accuracy() != 0 || empty.ShotsToWin() != 0 {
+		t.Fatalf("expected no stats, got %+v", empty)
+	}
+}
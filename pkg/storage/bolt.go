@@ -0,0 +1,523 @@
+package storage
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alfiehiscox/submarines/pkg/account"
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/alfiehiscox/submarines/pkg/rating"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	metaBucket  = []byte("meta")
+	gamesBucket = []byte("games")
+	movesBucket = []byte("moves")
+
+	accountsBucket = []byte("accounts")
+	namesBucket    = []byte("account_names")
+	sessionsBucket = []byte("sessions")
+
+	ratingsBucket = []byte("ratings")
+	historyBucket = []byte("rating_history")
+	ratedBucket   = []byte("rated_games")
+
+	accountGamesBucket = []byte("account_games")
+
+	chatBucket = []byte("chat")
+
+	versionKey = []byte("version")
+)
+
+// Migrations bring a database from one schema version to the next. The
+// version stored in the meta bucket is the number of migrations that
+// have run, so only ever append to this list.
+var migrations = []func(tx *bolt.Tx) error{
+	// 1: games by id, and a bucket of moves per game keyed by index
+	func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(gamesBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(movesBucket)
+		return err
+	},
+
+	// 2: accounts by id, account ids by lower case name, and sessions by
+	// hashed token
+	func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{accountsBucket, namesBucket, sessionsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+
+	// 3: latest rating by account, a bucket of ratings per account keyed
+	// by index, and the ids of games that have been rated
+	func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{ratingsBucket, historyBucket, ratedBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+
+	// 4: a bucket per account of the ids of games it has played, filled in
+	// for the games already stored
+	func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(accountGamesBucket); err != nil {
+			return err
+		}
+
+		return tx.Bucket(gamesBucket).ForEach(func(_, v []byte) error {
+			var record game.Record
+			if err := json.Unmarshal(v, &record); err != nil {
+				return err
+			}
+			return indexAccounts(tx, record)
+		})
+	},
+
+	// 5: a bucket of chat messages per game keyed by index, like moves
+	func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(chatBucket)
+		return err
+	},
+}
+
+// Bolt is a Repository kept in a single file on disk.
+type Bolt struct {
+	db *bolt.DB
+}
+
+// Opens or creates the database at path and migrates it to the latest
+// schema version.
+func OpenBolt(path string) (*Bolt, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Bolt{db: db}, nil
+}
+
+func migrate(db *bolt.DB) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists(metaBucket)
+		if err != nil {
+			return err
+		}
+
+		version := 0
+		if b := meta.Get(versionKey); b != nil {
+			version = int(binary.BigEndian.Uint32(b))
+		}
+
+		if version > len(migrations) {
+			return fmt.Errorf("database schema version %d is newer than this build supports (%d)", version, len(migrations))
+		}
+
+		for ; version < len(migrations); version++ {
+			if err := migrations[version](tx); err != nil {
+				return fmt.Errorf("migrating to schema version %d: %w", version+1, err)
+			}
+		}
+
+		return meta.Put(versionKey, index(version))
+	})
+}
+
+// Returns the schema version of the database.
+func (b *Bolt) Version() (int, error) {
+	version := 0
+	err := b.db.View(func(tx *bolt.Tx) error {
+		version = int(binary.BigEndian.Uint32(tx.Bucket(metaBucket).Get(versionKey)))
+		return nil
+	})
+	return version, err
+}
+
+func (b *Bolt) SaveGame(record game.Record) error {
+	moves, chat := record.Moves, record.Chat
+	record.Moves, record.Chat = nil, nil
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(gamesBucket).Put([]byte(record.ID), data); err != nil {
+			return err
+		}
+
+		if err := indexAccounts(tx, record); err != nil {
+			return err
+		}
+
+		if err := appendLog(tx, movesBucket, record.ID, moves); err != nil {
+			return err
+		}
+
+		return appendLog(tx, chatBucket, record.ID, chat)
+	})
+}
+
+func (b *Bolt) Game(id string) (game.Record, error) {
+	var record game.Record
+	err := b.db.View(func(tx *bolt.Tx) error {
+		var err error
+		record, err = readGame(tx, []byte(id))
+		return err
+	})
+	return record, err
+}
+
+func (b *Bolt) Games() ([]game.Record, error) {
+	var records []game.Record
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(gamesBucket).ForEach(func(id, _ []byte) error {
+			record, err := readGame(tx, id)
+			if err != nil {
+				return err
+			}
+			records = append(records, record)
+			return nil
+		})
+	})
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Created.Before(records[j].Created)
+	})
+
+	return records, err
+}
+
+func (b *Bolt) AccountGames(id string) ([]game.Record, error) {
+	var records []game.Record
+	err := b.db.View(func(tx *bolt.Tx) error {
+		played := tx.Bucket(accountGamesBucket).Bucket([]byte(id))
+		if played == nil {
+			return nil
+		}
+
+		return played.ForEach(func(game, _ []byte) error {
+			record, err := readGame(tx, game)
+			if err != nil {
+				return err
+			}
+			records = append(records, record)
+			return nil
+		})
+	})
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Created.Before(records[j].Created)
+	})
+
+	return records, err
+}
+
+func (b *Bolt) Moves(id string, since int) ([]game.Move, error) {
+	var moves []game.Move
+	err := b.db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(gamesBucket).Get([]byte(id)) == nil {
+			return ErrNotFound
+		}
+
+		var err error
+		moves, err = readLog[game.Move](tx, movesBucket, []byte(id), since)
+		return err
+	})
+	return moves, err
+}
+
+func (b *Bolt) SaveAccount(a account.Account) error {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		accounts, names := tx.Bucket(accountsBucket), tx.Bucket(namesBucket)
+
+		name := []byte(strings.ToLower(a.Name))
+		if id := names.Get(name); id != nil && string(id) != a.ID {
+			return ErrNameTaken
+		}
+
+		if old := accounts.Get([]byte(a.ID)); old != nil {
+			var previous account.Account
+			if err := json.Unmarshal(old, &previous); err != nil {
+				return err
+			}
+			if err := names.Delete([]byte(strings.ToLower(previous.Name))); err != nil {
+				return err
+			}
+		}
+
+		if err := names.Put(name, []byte(a.ID)); err != nil {
+			return err
+		}
+		return accounts.Put([]byte(a.ID), data)
+	})
+}
+
+func (b *Bolt) Account(id string) (account.Account, error) {
+	var a account.Account
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return get(tx.Bucket(accountsBucket), []byte(id), &a)
+	})
+	return a, err
+}
+
+func (b *Bolt) AccountByName(name string) (account.Account, error) {
+	var a account.Account
+	err := b.db.View(func(tx *bolt.Tx) error {
+		id := tx.Bucket(namesBucket).Get([]byte(strings.ToLower(name)))
+		if id == nil {
+			return ErrNotFound
+		}
+		return get(tx.Bucket(accountsBucket), id, &a)
+	})
+	return a, err
+}
+
+func (b *Bolt) SaveSession(s account.Session) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Put([]byte(s.TokenHash), data)
+	})
+}
+
+func (b *Bolt) Session(tokenHash string) (account.Session, error) {
+	var s account.Session
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return get(tx.Bucket(sessionsBucket), []byte(tokenHash), &s)
+	})
+	if err == nil && time.Now().After(s.Expires) {
+		return account.Session{}, ErrNotFound
+	}
+	return s, err
+}
+
+func (b *Bolt) DeleteSession(tokenHash string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Delete([]byte(tokenHash))
+	})
+}
+
+func (b *Bolt) AddRatings(changes []rating.Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		rated := tx.Bucket(ratedBucket)
+		if rated.Get([]byte(changes[0].Game)) != nil {
+			return ErrAlreadyRated
+		}
+		if err := rated.Put([]byte(changes[0].Game), []byte{}); err != nil {
+			return err
+		}
+
+		for _, change := range changes {
+			data, err := json.Marshal(change)
+			if err != nil {
+				return err
+			}
+
+			if err := tx.Bucket(ratingsBucket).Put([]byte(change.Account), data); err != nil {
+				return err
+			}
+
+			history, err := tx.Bucket(historyBucket).CreateBucketIfNotExists([]byte(change.Account))
+			if err != nil {
+				return err
+			}
+
+			next := 0
+			if k, _ := history.Cursor().Last(); k != nil {
+				next = int(binary.BigEndian.Uint32(k)) + 1
+			}
+			if err := history.Put(index(next), data); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (b *Bolt) Rating(account string) (rating.Change, error) {
+	var change rating.Change
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return get(tx.Bucket(ratingsBucket), []byte(account), &change)
+	})
+	return change, err
+}
+
+func (b *Bolt) RatingHistory(account string) ([]rating.Change, error) {
+	history := []rating.Change{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		stored := tx.Bucket(historyBucket).Bucket([]byte(account))
+		if stored == nil {
+			return nil
+		}
+
+		return stored.ForEach(func(_, v []byte) error {
+			var change rating.Change
+			if err := json.Unmarshal(v, &change); err != nil {
+				return err
+			}
+			history = append(history, change)
+			return nil
+		})
+	})
+	return history, err
+}
+
+func (b *Bolt) Ratings() ([]rating.Change, error) {
+	var latest []rating.Change
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(ratingsBucket).ForEach(func(_, v []byte) error {
+			var change rating.Change
+			if err := json.Unmarshal(v, &change); err != nil {
+				return err
+			}
+			latest = append(latest, change)
+			return nil
+		})
+	})
+
+	sortRatings(latest)
+	return latest, err
+}
+
+func (b *Bolt) Ping() error {
+	return b.db.View(func(tx *bolt.Tx) error {
+		return nil
+	})
+}
+
+func (b *Bolt) Close() error {
+	return b.db.Close()
+}
+
+func readGame(tx *bolt.Tx, id []byte) (game.Record, error) {
+	var record game.Record
+
+	data := tx.Bucket(gamesBucket).Get(id)
+	if data == nil {
+		return record, ErrNotFound
+	}
+
+	if err := json.Unmarshal(data, &record); err != nil {
+		return record, err
+	}
+
+	var err error
+	if record.Moves, err = readLog[game.Move](tx, movesBucket, id, 0); err != nil {
+		return record, err
+	}
+
+	record.Chat, err = readLog[game.Message](tx, chatBucket, id, 0)
+	return record, err
+}
+
+// Stores the items of a game's log, such as its moves, that aren't
+// stored yet. Logs only ever grow, so items already stored are skipped.
+func appendLog[T any](tx *bolt.Tx, bucket []byte, id string, items []T) error {
+	stored, err := tx.Bucket(bucket).CreateBucketIfNotExists([]byte(id))
+	if err != nil {
+		return err
+	}
+
+	next := 0
+	if k, _ := stored.Cursor().Last(); k != nil {
+		next = int(binary.BigEndian.Uint32(k)) + 1
+	}
+
+	for i := next; i < len(items); i++ {
+		data, err := json.Marshal(items[i])
+		if err != nil {
+			return err
+		}
+		if err := stored.Put(index(i), data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reads a game's log from index since onwards.
+func readLog[T any](tx *bolt.Tx, bucket, id []byte, since int) ([]T, error) {
+	items := []T{}
+
+	stored := tx.Bucket(bucket).Bucket(id)
+	if stored == nil {
+		return items, nil
+	}
+
+	c := stored.Cursor()
+	for k, v := c.Seek(index(since)); k != nil; k, v = c.Next() {
+		var item T
+		if err := json.Unmarshal(v, &item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// Adds the game to the list of each account seated in it.
+func indexAccounts(tx *bolt.Tx, record game.Record) error {
+	for _, seat := range record.Seats {
+		if seat.Account == "" {
+			continue
+		}
+
+		played, err := tx.Bucket(accountGamesBucket).CreateBucketIfNotExists([]byte(seat.Account))
+		if err != nil {
+			return err
+		}
+		if err := played.Put([]byte(record.ID), []byte{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decodes the JSON value at key into v.
+func get(bucket *bolt.Bucket, key []byte, v any) error {
+	data := bucket.Get(key)
+	if data == nil {
+		return ErrNotFound
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Keys sort in order of the index they encode.
+func index(i int) []byte {
+	key := make([]byte, 4)
+	binary.BigEndian.PutUint32(key, uint32(i))
+	return key
+}
@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alfiehiscox/submarines/pkg/account"
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/alfiehiscox/submarines/pkg/rating"
+)
+
+// Memory is a Repository that forgets everything when the process exits.
+// Useful for tests and throwaway servers.
+type Memory struct {
+	mu       sync.RWMutex
+	games    map[string]game.Record
+	accounts map[string]account.Account
+	names    map[string]string // lower case name to account id
+	sessions map[string]account.Session
+	ratings  map[string][]rating.Change // by account, oldest first
+	rated    map[string]bool
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		games:    make(map[string]game.Record),
+		accounts: make(map[string]account.Account),
+		names:    make(map[string]string),
+		sessions: make(map[string]account.Session),
+		ratings:  make(map[string][]rating.Change),
+		rated:    make(map[string]bool),
+	}
+}
+
+func (m *Memory) SaveGame(record game.Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	moves := m.games[record.ID].Moves
+	if len(record.Moves) > len(moves) {
+		moves = append(moves, record.Moves[len(moves):]...)
+	}
+
+	record = clone(record)
+	record.Moves = moves
+	m.games[record.ID] = record
+	return nil
+}
+
+func (m *Memory) Game(id string) (game.Record, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	record, ok := m.games[id]
+	if !ok {
+		return game.Record{}, ErrNotFound
+	}
+	return clone(record), nil
+}
+
+func (m *Memory) Games() ([]game.Record, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	records := make([]game.Record, 0, len(m.games))
+	for _, record := range m.games {
+		records = append(records, clone(record))
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Created.Before(records[j].Created)
+	})
+
+	return records, nil
+}
+
+func (m *Memory) AccountGames(id string) ([]game.Record, error) {
+	games, err := m.Games()
+	if err != nil {
+		return nil, err
+	}
+
+	played := games[:0]
+	for _, record := range games {
+		if seatOf(record, id) >= 0 {
+			played = append(played, record)
+		}
+	}
+	return played, nil
+}
+
+func (m *Memory) Moves(id string, since int) ([]game.Move, error) {
+	record, err := m.Game(id)
+	if err != nil {
+		return nil, err
+	}
+	return record.Moves[min(since, len(record.Moves)):], nil
+}
+
+func (m *Memory) SaveAccount(a account.Account) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name := strings.ToLower(a.Name)
+	if id, ok := m.names[name]; ok && id != a.ID {
+		return ErrNameTaken
+	}
+
+	if old, ok := m.accounts[a.ID]; ok {
+		delete(m.names, strings.ToLower(old.Name))
+	}
+
+	m.accounts[a.ID] = a
+	m.names[name] = a.ID
+	return nil
+}
+
+func (m *Memory) Account(id string) (account.Account, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	a, ok := m.accounts[id]
+	if !ok {
+		return account.Account{}, ErrNotFound
+	}
+	return a, nil
+}
+
+func (m *Memory) AccountByName(name string) (account.Account, error) {
+	m.mu.RLock()
+	id, ok := m.names[strings.ToLower(name)]
+	m.mu.RUnlock()
+
+	if !ok {
+		return account.Account{}, ErrNotFound
+	}
+	return m.Account(id)
+}
+
+func (m *Memory) SaveSession(s account.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[s.TokenHash] = s
+	return nil
+}
+
+func (m *Memory) Session(tokenHash string) (account.Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, ok := m.sessions[tokenHash]
+	if !ok || time.Now().After(s.Expires) {
+		return account.Session{}, ErrNotFound
+	}
+	return s, nil
+}
+
+func (m *Memory) DeleteSession(tokenHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, tokenHash)
+	return nil
+}
+
+func (m *Memory) AddRatings(changes []rating.Change) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if m.rated[changes[0].Game] {
+		return ErrAlreadyRated
+	}
+	m.rated[changes[0].Game] = true
+
+	for _, change := range changes {
+		m.ratings[change.Account] = append(m.ratings[change.Account], change)
+	}
+	return nil
+}
+
+func (m *Memory) Rating(account string) (rating.Change, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	history := m.ratings[account]
+	if len(history) == 0 {
+		return rating.Change{}, ErrNotFound
+	}
+	return history[len(history)-1], nil
+}
+
+func (m *Memory) RatingHistory(account string) ([]rating.Change, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]rating.Change{}, m.ratings[account]...), nil
+}
+
+func (m *Memory) Ratings() ([]rating.Change, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	latest := make([]rating.Change, 0, len(m.ratings))
+	for _, history := range m.ratings {
+		latest = append(latest, history[len(history)-1])
+	}
+
+	sortRatings(latest)
+	return latest, nil
+}
+
+func (m *Memory) Ping() error {
+	return nil
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
+
+// Copies the slices of a record so callers can't change what is stored.
+func clone(record game.Record) game.Record {
+	record.Moves = append([]game.Move(nil), record.Moves...)
+	record.Chat = append([]game.Message(nil), record.Chat...)
+	record.Reports = append([]game.Report(nil), record.Reports...)
+	for seat := range record.Seats {
+		record.Seats[seat].Fleet = append(record.Seats[seat].Fleet[:0:0], record.Seats[seat].Fleet...)
+	}
+	return record
+}
@@ -0,0 +1,130 @@
+// Package storage keeps games, their players and their move history
+// beyond the life of the process.
+package storage
+
+import (
+	"errors"
+	"log/slog"
+	"sort"
+
+	"github.com/alfiehiscox/submarines/pkg/account"
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/alfiehiscox/submarines/pkg/rating"
+)
+
+// Enough for every event of a whole game, so a persister is never dropped
+const PERSIST_BUFFER = 512
+
+var (
+	ErrNotFound  = errors.New("not found")
+	ErrNameTaken = errors.New("name is taken")
+
+	ErrAlreadyRated = errors.New("game already rated")
+)
+
+// Repository stores game records, accounts and sessions.
+// Implementations are safe for concurrent use.
+type Repository interface {
+	// Inserts or updates a game. Moves are append only: any moves past
+	// those already stored are added, the rest are left alone.
+	SaveGame(record game.Record) error
+
+	// Returns the game with id along with all its moves.
+	Game(id string) (game.Record, error)
+
+	// Returns every stored game, oldest first.
+	Games() ([]game.Record, error)
+
+	// Returns the games an account has a seat in, oldest first.
+	AccountGames(account string) ([]game.Record, error)
+
+	// Returns the moves of game id, skipping the first since.
+	Moves(id string, since int) ([]game.Move, error)
+
+	// Inserts or updates an account. Names are unique regardless of case,
+	// ErrNameTaken is returned if another account has the name.
+	SaveAccount(a account.Account) error
+
+	Account(id string) (account.Account, error)
+	AccountByName(name string) (account.Account, error)
+
+	SaveSession(s account.Session) error
+
+	// Returns the session with the hashed token, ErrNotFound if it has
+	// expired.
+	Session(tokenHash string) (account.Session, error)
+
+	DeleteSession(tokenHash string) error
+
+	// Records the ratings of the accounts that played a game. Returns
+	// ErrAlreadyRated if the game's ratings have been added before.
+	AddRatings(changes []rating.Change) error
+
+	// Returns the latest rating of an account, ErrNotFound if it has never
+	// played a rated game.
+	Rating(account string) (rating.Change, error)
+
+	// Returns every rating an account has had, oldest first.
+	RatingHistory(account string) ([]rating.Change, error)
+
+	// Returns the latest rating of every rated account, highest first.
+	Ratings() ([]rating.Change, error)
+
+	// Checks the repository can still be read, such as before telling a
+	// load balancer the server is ready.
+	Ping() error
+
+	Close() error
+}
+
+// Saves g to repo whenever it changes, returning once it has finished
+// or been suspended and saved for the last time.
+func Persist(repo Repository, g *game.Game, log *slog.Logger) {
+	for {
+		events, cancel := g.Subscribe(PERSIST_BUFFER)
+
+		// Catches anything that happened before subscribing
+		if done := save(repo, g, log); done {
+			cancel()
+			return
+		}
+
+		for range events {
+			if done := save(repo, g, log); done {
+				cancel()
+				return
+			}
+		}
+
+		// Dropped for falling behind, so subscribe and save again
+		cancel()
+	}
+}
+
+func save(repo Repository, g *game.Game, log *slog.Logger) bool {
+	// Checked first, as nothing changes once suspended
+	suspended := g.Snapshot().Suspended
+
+	record := g.Record()
+	if err := repo.SaveGame(record); err != nil {
+		log.Error("Error saving game", "game", g.ID, "error", err)
+	}
+	return suspended || record.Phase == game.FINISHED
+}
+
+// Sorts ratings highest first.
+func sortRatings(ratings []rating.Change) {
+	sort.Slice(ratings, func(i, j int) bool {
+		return ratings[i].Rating.Rating > ratings[j].Rating.Rating
+	})
+}
+
+// Returns the seat of the account in the game, -1 if it has none.
+func seatOf(record game.Record, account string) int {
+	for seat, s := range record.Seats {
+		if account != "" && s.Account == account {
+			return seat
+		}
+	}
+	return -1
+}
@@ -0,0 +1,336 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alfiehiscox/submarines/pkg/account"
+	"github.com/alfiehiscox/submarines/pkg/cell"
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/alfiehiscox/submarines/pkg/rating"
+	bolt "go.etcd.io/bbolt"
+)
+
+func repositories(t *testing.T) map[string]Repository {
+	t.Helper()
+
+	bolt, err := OpenBolt(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed in set up: %s", err)
+	}
+	t.Cleanup(func() { bolt.Close() })
+
+	return map[string]Repository{
+		"memory": NewMemory(),
+		"bolt":   bolt,
+	}
+}
+
+// Returns a game where seat 0 has fired once.
+func playedGame(t *testing.T) *game.Game {
+	t.Helper()
+
+	g := game.New("test_game")
+	for seat := 0; seat < game.SEATS; seat++ {
+		g.Join("test_player")
+		g.RandomizeFleet(seat)
+		g.Ready(seat)
+	}
+
+	if _, err := g.Fire(0, cell.Coordinate{1, 1}); err != nil {
+		t.Fatalf("failed in set up: %s", err)
+	}
+
+	return g
+}
+
+func TestSaveAndRestore(t *testing.T) {
+	for name, repo := range repositories(t) {
+		t.Run(name, func(t *testing.T) {
+			g := playedGame(t)
+			_, token, _ := game.New("other").Join("unused")
+
+			if err := repo.SaveGame(g.Record()); err != nil {
+				t.Fatalf("err should be nil: %s", err)
+			}
+
+			g.Fire(1, cell.Coordinate{2, 2})
+			if err := repo.SaveGame(g.Record()); err != nil {
+				t.Fatalf("err should be nil: %s", err)
+			}
+
+			record, err := repo.Game(g.ID)
+			if err != nil {
+				t.Fatalf("err should be nil: %s", err)
+			}
+
+			restored := game.Restore(record)
+			if len(restored.Snapshot().Moves) != 2 || restored.Snapshot().Turn != 0 {
+				t.Fatalf("expected 2 moves and seat 0 to fire, got %v", restored.Snapshot())
+			}
+
+			for seat := 0; seat < game.SEATS; seat++ {
+				for i, c := range g.Fleet(seat) {
+					if restored.Fleet(seat)[i].Occupied != c.Occupied {
+						t.Fatalf("fleet of seat %d differs at cell %d", seat, i)
+					}
+				}
+			}
+
+			if _, ok := restored.Seat(token); ok {
+				t.Fatal("a token from another game should not match a seat")
+			}
+
+			moves, err := repo.Moves(g.ID, 1)
+			if err != nil || len(moves) != 1 || moves[0].Coordinate != (cell.Coordinate{2, 2}) {
+				t.Fatalf("expected the second move, got %v %v", moves, err)
+			}
+
+			if _, err := repo.Game("missing"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("expected ErrNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestChatSaved(t *testing.T) {
+	for name, repo := range repositories(t) {
+		t.Run(name, func(t *testing.T) {
+			g := playedGame(t)
+			g.Say(0, "good luck")
+			repo.SaveGame(g.Record())
+
+			g.Say(1, "you too")
+			g.Report(0)
+			repo.SaveGame(g.Record())
+
+			record, err := repo.Game(g.ID)
+			if err != nil {
+				t.Fatalf("err should be nil: %s", err)
+			}
+
+			if len(record.Chat) != 2 || record.Chat[1].Text != "you too" || len(record.Reports) != 1 {
+				t.Fatalf("expected both messages and the report, got %v %v", record.Chat, record.Reports)
+			}
+		})
+	}
+}
+
+func TestGamesOldestFirst(t *testing.T) {
+	for name, repo := range repositories(t) {
+		t.Run(name, func(t *testing.T) {
+			older, newer := game.New("older"), game.New("newer")
+			older.Created = newer.Created.Add(-time.Hour)
+
+			repo.SaveGame(newer.Record())
+			repo.SaveGame(older.Record())
+
+			games, err := repo.Games()
+			if err != nil {
+				t.Fatalf("err should be nil: %s", err)
+			}
+
+			if len(games) != 2 || games[0].ID != "older" {
+				t.Fatalf("expected older game first, got %v", games)
+			}
+		})
+	}
+}
+
+func TestBoltMigrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	for i := 0; i < 2; i++ {
+		repo, err := OpenBolt(path)
+		if err != nil {
+			t.Fatalf("err should be nil: %s", err)
+		}
+
+		version, err := repo.Version()
+		if err != nil || version != len(migrations) {
+			t.Fatalf("expected version %d, got %d %v", len(migrations), version, err)
+		}
+		repo.Close()
+	}
+}
+
+func TestPersist(t *testing.T) {
+	repo := NewMemory()
+	g := playedGame(t)
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	done := make(chan struct{})
+	go func() {
+		Persist(repo, g, log)
+		close(done)
+	}()
+
+	g.Resign(1)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("persist did not return after the game finished")
+	}
+
+	record, err := repo.Game(g.ID)
+	if err != nil || record.Phase != game.FINISHED || len(record.Moves) != 1 {
+		t.Fatalf("expected the finished game to be saved, got %v %v", record, err)
+	}
+}
+
+func TestAccounts(t *testing.T) {
+	for name, repo := range repositories(t) {
+		t.Run(name, func(t *testing.T) {
+			guest, _ := account.NewGuest()
+			if err := repo.SaveAccount(guest); err != nil {
+				t.Fatalf("err should be nil: %s", err)
+			}
+
+			// Registering renames the guest, freeing its old name
+			renamed := guest
+			renamed.Name = "Admiral"
+			if err := repo.SaveAccount(renamed); err != nil {
+				t.Fatalf("err should be nil: %s", err)
+			}
+
+			if _, err := repo.AccountByName(guest.Name); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("expected the old name to be free, got %v", err)
+			}
+
+			found, err := repo.AccountByName("admiral")
+			if err != nil || found.ID != guest.ID {
+				t.Fatalf("expected to find the account ignoring case, got %v %v", found, err)
+			}
+
+			other, _ := account.NewGuest()
+			other.Name = "ADMIRAL"
+			if err := repo.SaveAccount(other); !errors.Is(err, ErrNameTaken) {
+				t.Fatalf("expected ErrNameTaken, got %v", err)
+			}
+
+			session, _, _ := account.NewSession(found)
+			repo.SaveSession(session)
+			if s, err := repo.Session(session.TokenHash); err != nil || s.Account != guest.ID {
+				t.Fatalf("expected the session, got %v %v", s, err)
+			}
+
+			repo.DeleteSession(session.TokenHash)
+			if _, err := repo.Session(session.TokenHash); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("expected ErrNotFound, got %v", err)
+			}
+
+			expired := account.Session{TokenHash: "expired", Account: guest.ID, Expires: time.Now().Add(-time.Minute)}
+			repo.SaveSession(expired)
+			if _, err := repo.Session(expired.TokenHash); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("expected expired sessions to be gone, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRatings(t *testing.T) {
+	for name, repo := range repositories(t) {
+		t.Run(name, func(t *testing.T) {
+			if _, err := repo.Rating("first"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("expected ErrNotFound, got %v", err)
+			}
+
+			first, second := rating.Game(rating.Default(), rating.Default(), true)
+			changes := []rating.Change{
+				{Account: "first", Game: "game_1", Rating: first},
+				{Account: "second", Game: "game_1", Rating: second},
+			}
+
+			if err := repo.AddRatings(changes); err != nil {
+				t.Fatalf("err should be nil: %s", err)
+			}
+
+			if err := repo.AddRatings(changes); !errors.Is(err, ErrAlreadyRated) {
+				t.Fatalf("expected ErrAlreadyRated, got %v", err)
+			}
+
+			second, first = rating.Game(second, first, true)
+			repo.AddRatings([]rating.Change{
+				{Account: "first", Game: "game_2", Rating: first},
+				{Account: "second", Game: "game_2", Rating: second},
+			})
+
+			history, err := repo.RatingHistory("first")
+			if err != nil || len(history) != 2 || history[1].Game != "game_2" {
+				t.Fatalf("expected two ratings in order, got %v %v", history, err)
+			}
+
+			latest, err := repo.Rating("first")
+			if err != nil || latest.Rating != first {
+				t.Fatalf("expected the latest rating, got %v %v", latest, err)
+			}
+
+			ratings, err := repo.Ratings()
+			if err != nil || len(ratings) != 2 || ratings[0].Rating.Rating < ratings[1].Rating.Rating {
+				t.Fatalf("expected ratings highest first, got %v %v", ratings, err)
+			}
+		})
+	}
+}
+
+func TestAccountGames(t *testing.T) {
+	for name, repo := range repositories(t) {
+		t.Run(name, func(t *testing.T) {
+			g := game.New("test_game")
+			g.JoinAs("first", "first_account")
+			repo.SaveGame(g.Record())
+			repo.SaveGame(game.New("other_game").Record())
+
+			games, err := repo.AccountGames("first_account")
+			if err != nil || len(games) != 1 || games[0].ID != g.ID {
+				t.Fatalf("expected the account's game, got %v %v", games, err)
+			}
+
+			if games, _ := repo.AccountGames(""); len(games) != 0 {
+				t.Fatalf("expected no games for anonymous seats, got %v", games)
+			}
+		})
+	}
+}
+
+// Games saved before accounts were indexed are found once migrated
+func TestBoltMigrationBackfillsAccountGames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	repo, err := OpenBolt(path)
+	if err != nil {
+		t.Fatalf("failed in set up: %s", err)
+	}
+
+	g := game.New("test_game")
+	g.JoinAs("first", "first_account")
+	repo.SaveGame(g.Record())
+
+	// Wind back to before migration 4
+	err = repo.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(accountGamesBucket); err != nil {
+			return err
+		}
+		return tx.Bucket(metaBucket).Put(versionKey, index(3))
+	})
+	if err != nil {
+		t.Fatalf("failed in set up: %s", err)
+	}
+	repo.Close()
+
+	repo, err = OpenBolt(path)
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+	defer repo.Close()
+
+	games, err := repo.AccountGames("first_account")
+	if err != nil || len(games) != 1 {
+		t.Fatalf("expected the game to be indexed, got %v %v", games, err)
+	}
+}
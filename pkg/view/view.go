@@ -0,0 +1,132 @@
+// Package view projects game state into what a single viewer is allowed to
+// see. pkg/html only renders boards from these, and nothing here takes an
+// opponent's fleet, so their ships can't end up in a response by mistake.
+package view
+
+import (
+	"github.com/alfiehiscox/submarines/pkg/board"
+	"github.com/alfiehiscox/submarines/pkg/cell"
+	"github.com/alfiehiscox/submarines/pkg/game"
+	"github.com/alfiehiscox/submarines/pkg/player"
+)
+
+// What a viewer knows about each cell of a fleet's board, by index
+type Board []cell.State
+
+// A seated player's view of a game: their own fleet along with the shots
+// fired at it, and the opponent's waters with only the player's own shots.
+type Player struct {
+	game.Snapshot
+	Seat   int
+	Fleet  Board
+	Waters Board
+}
+
+// A spectator's view of a game, with each fleet showing only the shots
+// fired at it.
+type Spectator struct {
+	game.Snapshot
+	Fleets [game.SEATS]Board
+}
+
+// A finished game after its first N moves. Both fleets are shown in full,
+// as the game is over.
+type Replay struct {
+	ID        string
+	Names     [game.SEATS]string
+	Moves     []game.Move
+	Winner    int
+	Forfeited bool
+	N         int
+	Fleets    [game.SEATS]Board
+}
+
+// Ships being placed by their owner, by their number in the ship gallery
+// counting from 1 as in game.FLEET, along with the ship to place next,
+// which way it faces and the last one placed, 0 for none.
+type Placement struct {
+	Ships       map[int]game.Ship
+	Chosen      int
+	Orientation cell.Orientation
+	Last        int
+	Fleet       Board
+}
+
+// Returns seat's view of the game in s, given the seat's own fleet.
+func ForPlayer(s game.Snapshot, seat int, fleet board.Board) Player {
+	return Player{
+		Snapshot: s,
+		Seat:     seat,
+		Fleet:    Fleet(fleet, s.Shots(seat)),
+		Waters:   Board(s.Shots(1 - seat)),
+	}
+}
+
+func ForSpectator(s game.Snapshot) Spectator {
+	v := Spectator{Snapshot: s}
+	for seat := range v.Fleets {
+		v.Fleets[seat] = Board(s.Shots(seat))
+	}
+	return v
+}
+
+// Returns the view of r after its first n moves, or false if r isn't
+// finished, when its fleets are still hidden.
+func ForReplay(r game.Record, n int) (Replay, bool) {
+	if r.Phase != game.FINISHED {
+		return Replay{}, false
+	}
+
+	n = max(0, min(n, len(r.Moves)))
+	v := Replay{
+		ID:        r.ID,
+		Moves:     r.Moves,
+		Winner:    r.Winner,
+		Forfeited: r.Forfeited,
+		N:         n,
+	}
+
+	boards := r.Boards(n)
+	for seat := range r.Seats {
+		v.Names[seat] = r.Seats[seat].Name
+		v.Fleets[seat] = Board(boards[seat])
+	}
+
+	return v, true
+}
+
+// Returns the placement of ships, or an error if any of them overlap or
+// go off the board.
+func ForPlacement(ships map[int]game.Ship, chosen int, orientation cell.Orientation, last int) (Placement, error) {
+	placed := player.NewPlayer("")
+	for _, ship := range ships {
+		if err := placed.PlaceShip(ship.Size, ship.Orientation, ship.Coordinate); err != nil {
+			return Placement{}, err
+		}
+	}
+
+	return Placement{
+		Ships:       ships,
+		Chosen:      chosen,
+		Orientation: orientation,
+		Last:        last,
+		Fleet:       Fleet(placed.PlayerBoard, nil),
+	}, nil
+}
+
+// Returns the owner's view of fleet, with shots fired at it laid over its
+// ships. shots may be nil while nobody has fired.
+func Fleet(fleet board.Board, shots []cell.State) Board {
+	b := make(Board, cell.BOARD_WIDTH*cell.BOARD_HEIGHT)
+	for i := range b {
+		switch {
+		case shots != nil && shots[i] != cell.UNKNOWN:
+			b[i] = shots[i]
+		case i < len(fleet) && fleet[i].Occupied:
+			b[i] = cell.SHIP
+		default:
+			b[i] = cell.UNKNOWN
+		}
+	}
+	return b
+}
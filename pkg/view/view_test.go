@@ -0,0 +1,147 @@
+package view
+
+import (
+	"testing"
+
+	"github.com/alfiehiscox/submarines/pkg/cell"
+	"github.com/alfiehiscox/submarines/pkg/game"
+)
+
+// Returns a game being played, where seat 0 has missed and seat 1 has hit.
+func playing(t *testing.T) *game.Game {
+	t.Helper()
+
+	g := game.New("test")
+	for _, name := range []string{"alpha", "beta"} {
+		seat, _, err := g.Join(name)
+		if err != nil {
+			t.Fatalf("failed in set up: %s", err)
+		}
+		g.RandomizeFleet(seat)
+		g.Ready(seat)
+	}
+
+	miss, hit := -1, -1
+	for i, c := range g.Fleet(1) {
+		if !c.Occupied {
+			miss = i
+			break
+		}
+	}
+	for i, c := range g.Fleet(0) {
+		if c.Occupied {
+			hit = i
+			break
+		}
+	}
+
+	if _, err := g.Fire(0, coordinate(miss)); err != nil {
+		t.Fatalf("failed in set up: %s", err)
+	}
+	if _, err := g.Fire(1, coordinate(hit)); err != nil {
+		t.Fatalf("failed in set up: %s", err)
+	}
+
+	return g
+}
+
+func coordinate(i int) cell.Coordinate {
+	return cell.Coordinate{i % cell.BOARD_WIDTH, i / cell.BOARD_WIDTH}
+}
+
+func TestForPlayer(t *testing.T) {
+	g := playing(t)
+
+	for seat := range game.SEATS {
+		v := ForPlayer(g.Snapshot(), seat, g.Fleet(seat))
+		fleet := g.Fleet(seat)
+		shots := g.Snapshot().Shots(seat)
+
+		for i := range v.Fleet {
+			switch {
+			case shots[i] != cell.UNKNOWN && v.Fleet[i] != shots[i]:
+				t.Fatalf("seat %d: expected shot at %d to show, got %s", seat, i, v.Fleet[i])
+			case shots[i] == cell.UNKNOWN && fleet[i].Occupied != (v.Fleet[i] == cell.SHIP):
+				t.Fatalf("seat %d: expected own ship at %d to show, got %s", seat, i, v.Fleet[i])
+			}
+		}
+
+		for i, state := range v.Waters {
+			if state == cell.SHIP {
+				t.Fatalf("seat %d: opponent's ship at %d leaked", seat, i)
+			}
+		}
+	}
+}
+
+func TestForSpectator(t *testing.T) {
+	g := playing(t)
+
+	v := ForSpectator(g.Snapshot())
+	for seat, fleet := range v.Fleets {
+		for i, state := range fleet {
+			if state == cell.SHIP {
+				t.Fatalf("seat %d's ship at %d leaked to spectators", seat, i)
+			}
+		}
+	}
+}
+
+func TestForReplay(t *testing.T) {
+	g := playing(t)
+
+	if _, ok := ForReplay(g.Record(), 0); ok {
+		t.Fatal("expected no replay of a game in progress")
+	}
+
+	g.Resign(1)
+
+	v, ok := ForReplay(g.Record(), 10)
+	if !ok || v.N != 2 {
+		t.Fatalf("expected a replay clamped to the moves made, got %v %d", ok, v.N)
+	}
+
+	for seat, fleet := range v.Fleets {
+		ships := 0
+		for _, state := range fleet {
+			if state == cell.SHIP || state == cell.HIT {
+				ships++
+			}
+		}
+		if ships == 0 {
+			t.Fatalf("expected seat %d's fleet to be shown once finished", seat)
+		}
+	}
+}
+
+func TestForPlacement(t *testing.T) {
+	ships := map[int]game.Ship{
+		1: {Size: 5, Orientation: cell.HORIZONTAL, Coordinate: cell.Coordinate{0, 0}},
+		5: {Size: 2, Orientation: cell.VERTICAL, Coordinate: cell.Coordinate{9, 8}},
+	}
+
+	v, err := ForPlacement(ships, 2, cell.HORIZONTAL, 5)
+	if err != nil {
+		t.Fatalf("expected the ships to fit, got %s", err)
+	}
+
+	placed := 0
+	for _, state := range v.Fleet {
+		if state == cell.SHIP {
+			placed++
+		}
+	}
+	if placed != 7 {
+		t.Fatalf("expected 7 squares of ships, got %d", placed)
+	}
+
+	ships[2] = game.Ship{Size: 4, Orientation: cell.VERTICAL, Coordinate: cell.Coordinate{3, 0}}
+	if _, err := ForPlacement(ships, 3, cell.HORIZONTAL, 2); err == nil {
+		t.Fatal("expected overlapping ships to be refused")
+	}
+
+	ships[2] = game.Ship{Size: 4, Orientation: cell.HORIZONTAL, Coordinate: cell.Coordinate{7, 3}}
+	if _, err := ForPlacement(ships, 3, cell.HORIZONTAL, 2); err == nil {
+		t.Fatal("expected a ship off the board to be refused")
+	}
+}
@@ -0,0 +1,92 @@
+// Moves around boards, which are ARIA grids, with the arrow keys. Only
+// one cell of a board is in the tab order at a time, the one last moved
+// to. Enter or Space clicks the cell, firing at it or placing a ship, and
+// R turns the ship being placed.
+const WIDTH = 10;
+
+function cellsOf(grid) {
+  return Array.from(grid.querySelectorAll("[role=gridcell]"));
+}
+
+function focusCell(grid, index) {
+  const cells = cellsOf(grid);
+  const next = cells[Math.max(0, Math.min(index, cells.length - 1))];
+  if (!next) {
+    return;
+  }
+  for (const cell of cells) {
+    cell.tabIndex = cell === next ? 0 : -1;
+  }
+  next.focus();
+}
+
+document.addEventListener("keydown", (event) => {
+  if (event.target.matches("input[type=text], input[type=password], textarea")) {
+    return;
+  }
+
+  if (event.key === "r" || event.key === "R") {
+    const placement = document.getElementById("placement");
+    const unchecked = placement && placement.querySelector("input[name=orientation]:not(:checked)");
+    if (unchecked) {
+      unchecked.checked = true;
+      unchecked.dispatchEvent(new Event("change", { bubbles: true }));
+      event.preventDefault();
+    }
+    return;
+  }
+
+  const cell = event.target.closest("[role=gridcell]");
+  const grid = cell && cell.closest("[role=grid]");
+  if (!grid) {
+    return;
+  }
+
+  const cells = cellsOf(grid);
+  const index = cells.indexOf(cell);
+  const moves = {
+    ArrowLeft: index % WIDTH === 0 ? index : index - 1,
+    ArrowRight: index % WIDTH === WIDTH - 1 ? index : index + 1,
+    ArrowUp: index - WIDTH < 0 ? index : index - WIDTH,
+    ArrowDown: index + WIDTH >= cells.length ? index : index + WIDTH,
+    Home: index - (index % WIDTH),
+    End: index - (index % WIDTH) + WIDTH - 1,
+  };
+
+  if (event.key in moves) {
+    focusCell(grid, moves[event.key]);
+    event.preventDefault();
+  } else if (event.key === "Enter" || event.key === " ") {
+    cell.click();
+    event.preventDefault();
+  }
+});
+
+// Boards are swapped out as the game goes on. Focus goes back to the same
+// cell of the new board, so players don't lose their place.
+let focused = null;
+
+function remember() {
+  const cell = document.activeElement && document.activeElement.closest("[role=gridcell]");
+  const grid = cell && cell.closest("[role=grid]");
+  if (grid && grid.id) {
+    focused = { grid: grid.id, index: cellsOf(grid).indexOf(cell) };
+  }
+}
+
+function restore() {
+  const last = focused;
+  focused = null;
+  if (!last || (document.activeElement && document.activeElement !== document.body)) {
+    return;
+  }
+  const grid = document.getElementById(last.grid);
+  if (grid) {
+    focusCell(grid, last.index);
+  }
+}
+
+document.addEventListener("htmx:beforeRequest", remember);
+document.addEventListener("htmx:sseBeforeMessage", remember);
+document.addEventListener("htmx:afterSettle", restore);
+document.addEventListener("htmx:sseMessage", restore);
@@ -0,0 +1,8 @@
+// Sends the CSRF cookie back as a header with every htmx request, which
+// the server checks before changing anything.
+document.addEventListener("htmx:configRequest", (event) => {
+  const token = document.cookie.match(/(?:^|; )csrf=([^;]*)/);
+  if (token) {
+    event.detail.headers["X-CSRF-Token"] = token[1];
+  }
+});
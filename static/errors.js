@@ -0,0 +1,15 @@
+// Shows errors the server points somewhere with HX-Retarget, which htmx
+// would otherwise drop along with every other error response.
+document.addEventListener("htmx:beforeSwap", (event) => {
+  if (event.detail.isError && event.detail.xhr.getResponseHeader("HX-Retarget")) {
+    event.detail.shouldSwap = true;
+  }
+});
+
+// Clears the last error once something works.
+document.addEventListener("htmx:afterRequest", (event) => {
+  const error = document.getElementById("error");
+  if (error && event.detail.successful) {
+    error.replaceChildren();
+  }
+});
@@ -0,0 +1,113 @@
+// Package static holds the stylesheet and scripts the site serves,
+// embedded so the binary runs from any directory and without a network.
+// Files are served under names containing a hash of their content, so
+// browsers can cache them forever.
+package static
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+)
+
+// Where the files are served from
+const PREFIX = "/static/"
+
+// How long browsers may cache files served under their hashed name
+const IMMUTABLE = "public, max-age=31536000, immutable"
+
+// Scripts in vendor are fetched by `make vendor`
+//
+//go:embed app.css board.js csrf.js errors.js all:vendor
+var files embed.FS
+
+// Assets serves a tree of files under content hashed names, such as
+// app.1a2b3c4d5e.css for app.css. The plain names are still served, but
+// browsers have to check they are up to date.
+type Assets struct {
+	fsys   fs.FS
+	hashed map[string]string // name to hashed name
+	names  map[string]string // hashed name to name
+}
+
+// Hashes every file in fsys.
+func New(fsys fs.FS) (*Assets, error) {
+	a := &Assets{
+		fsys:   fsys,
+		hashed: make(map[string]string),
+		names:  make(map[string]string),
+	}
+
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		// Skips this file too when serving from disk
+		if err != nil || d.IsDir() || strings.HasPrefix(d.Name(), ".") || path.Ext(name) == ".go" {
+			return err
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		sum := sha256.Sum256(data)
+		ext := path.Ext(name)
+		hashed := strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(sum[:5]) + ext
+
+		a.hashed[name] = hashed
+		a.names[hashed] = name
+		return nil
+	})
+
+	return a, err
+}
+
+var embedded = sync.OnceValue(func() *Assets {
+	a, err := New(files)
+	if err != nil {
+		panic("static: hashing embedded files: " + err.Error())
+	}
+	return a
+})
+
+// Returns the files built into the binary.
+func Embedded() *Assets {
+	return embedded()
+}
+
+// Whether there is a file called name.
+func (a *Assets) Has(name string) bool {
+	_, ok := a.hashed[name]
+	return ok
+}
+
+// Returns the URL to load the file called name from.
+func (a *Assets) URL(name string) string {
+	if hashed, ok := a.hashed[name]; ok {
+		return PREFIX + hashed
+	}
+	return PREFIX + name
+}
+
+// Serves the files, expecting PREFIX to have been stripped from the path.
+func (a *Assets) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/")
+
+	if original, ok := a.names[name]; ok {
+		w.Header().Set("Cache-Control", IMMUTABLE)
+		http.ServeFileFS(w, r, a.fsys, original)
+		return
+	}
+
+	if a.Has(name) {
+		w.Header().Set("Cache-Control", "no-cache")
+		http.ServeFileFS(w, r, a.fsys, name)
+		return
+	}
+
+	http.NotFound(w, r)
+}
@@ -0,0 +1,47 @@
+package static
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestAssets(t *testing.T) {
+	a, err := New(fstest.MapFS{
+		"app.css":       {Data: []byte("body {}")},
+		"vendor/app.js": {Data: []byte("let a = 1")},
+	})
+	if err != nil {
+		t.Fatalf("err should be nil: %s", err)
+	}
+
+	url := a.URL("app.css")
+	if !strings.HasPrefix(url, PREFIX+"app.") || !strings.HasSuffix(url, ".css") || url == PREFIX+"app.css" {
+		t.Fatalf("expected a hashed url, got %q", url)
+	}
+
+	for path, want := range map[string]string{
+		url:                    IMMUTABLE,
+		PREFIX + "app.css":     "no-cache",
+		a.URL("vendor/app.js"): IMMUTABLE,
+		PREFIX + "missing.css": "",
+	} {
+		w := httptest.NewRecorder()
+		http.StripPrefix(PREFIX, a).ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+
+		if got := w.Header().Get("Cache-Control"); got != want {
+			t.Fatalf("expected %s to be served with %q, got %q", path, want, got)
+		}
+		if want == "" && w.Code != http.StatusNotFound {
+			t.Fatalf("expected 404 for %s, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestEmbedded(t *testing.T) {
+	if a := Embedded(); !a.Has("app.css") || !a.Has("csrf.js") {
+		t.Fatal("expected the stylesheet and scripts to be built in")
+	}
+}